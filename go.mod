@@ -5,4 +5,5 @@ go 1.14
 require (
 	github.com/google/go-cmp v0.5.1
 	github.com/stretchr/testify v1.6.1
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
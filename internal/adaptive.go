@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+const (
+	adaptivePixelSamples  = 5
+	adaptiveRefineSamples = 8
+)
+
+func validateAdaptive(c Camera, opts RenderOptions) error {
+	if !opts.Adaptive {
+		return nil
+	}
+
+	if opts.MaxSamples < adaptivePixelSamples {
+		return fmt.Errorf("adaptive sampling needs at least %d max samples per pixel", adaptivePixelSamples)
+	}
+
+	if c.Samples > 1 || c.Sampling != SampleStratified || c.Filter != FilterBox || c.FilterRadius > 0 {
+		return errors.New("adaptive sampling places and averages its own samples, so camera samples, sampling and filter must stay at their defaults")
+	}
+
+	return nil
+}
+
+type adaptiveSampler struct {
+	c            Camera
+	w            World
+	opts         RenderOptions
+	integrator   Integrator
+	rng          *rand.Rand
+	scale        int
+	cache        map[[2]int]adaptiveSample
+	samples      int
+	pixelSamples int
+}
+
+func newAdaptiveSampler(c Camera, w World, opts RenderOptions, rng *rand.Rand) *adaptiveSampler {
+	depth := opts.AdaptiveDepth
+
+	if depth < 0 {
+		depth = 0
+	}
+
+	return &adaptiveSampler{
+		c:          c,
+		w:          w,
+		opts:       opts,
+		integrator: opts.integrator(),
+		rng:        rng,
+		scale:      1 << uint(depth),
+		cache:      make(map[[2]int]adaptiveSample),
+	}
+}
+
+type adaptiveSample struct {
+	color Color
+	alpha float64
+}
+
+func (a *adaptiveSampler) sampleAt(ix, iy int) adaptiveSample {
+	key := [2]int{ix, iy}
+
+	if sample, ok := a.cache[key]; ok {
+		return sample
+	}
+
+	s := CameraSample{
+		X: float64(ix) / float64(a.scale),
+		Y: float64(iy) / float64(a.scale),
+	}
+
+	if a.c.HasLens() {
+		s.LensX, s.LensY = a.c.sampleLens(a.rng)
+	}
+
+	if a.c.HasShutter() {
+		s.Time = a.rng.Float64()
+	}
+
+	color, alpha := cameraRadiance(a.w, a.c, 0, 0, s, a.integrator, a.opts, a.rng)
+	sample := adaptiveSample{color, alpha}
+
+	a.cache[key] = sample
+	a.samples++
+	a.pixelSamples++
+
+	return sample
+}
+
+func (a *adaptiveSampler) PixelColor(px, py int) (Color, float64) {
+	a.pixelSamples = 0
+	sample := a.region(px*a.scale, py*a.scale, a.scale)
+
+	return sample.color, sample.alpha
+}
+
+func (a *adaptiveSampler) region(x0, y0, size int) adaptiveSample {
+	half := size / 2
+	corners := []adaptiveSample{
+		a.sampleAt(x0, y0),
+		a.sampleAt(x0+size, y0),
+		a.sampleAt(x0, y0+size),
+		a.sampleAt(x0+size, y0+size),
+	}
+
+	if half == 0 {
+		return averageSamples(corners)
+	}
+
+	center := a.sampleAt(x0+half, y0+half)
+	colors := []Color{corners[0].color, corners[1].color, corners[2].color, corners[3].color, center.color}
+
+	if SampleContrast(colors) > a.opts.AdaptiveThreshold && a.pixelSamples+adaptiveRefineSamples <= a.opts.MaxSamples {
+		return averageSamples([]adaptiveSample{
+			a.region(x0, y0, half),
+			a.region(x0+half, y0, half),
+			a.region(x0, y0+half, half),
+			a.region(x0+half, y0+half, half),
+		})
+	}
+
+	return averageSamples([]adaptiveSample{averageSamples(corners), center})
+}
+
+func averageSamples(samples []adaptiveSample) adaptiveSample {
+	var result adaptiveSample
+
+	for _, s := range samples {
+		result.color = AddColors(result.color, s.color)
+		result.alpha += s.alpha
+	}
+
+	n := float64(len(samples))
+
+	return adaptiveSample{ColorScalarDivide(result.color, n), result.alpha / n}
+}
+
+func SampleContrast(colors []Color) float64 {
+	if len(colors) == 0 {
+		return 0
+	}
+
+	lo, hi := colors[0], colors[0]
+
+	for _, c := range colors[1:] {
+		lo = NewColor(math.Min(lo.R, c.R), math.Min(lo.G, c.G), math.Min(lo.B, c.B))
+		hi = NewColor(math.Max(hi.R, c.R), math.Max(hi.G, c.G), math.Max(hi.B, c.B))
+	}
+
+	return math.Max(math.Max(hi.R-lo.R, hi.G-lo.G), hi.B-lo.B)
+}
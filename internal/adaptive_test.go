@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func flatSphereWorld() World {
+	w := NewWorld()
+	w.Lights = append(w.Lights, NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1)))
+
+	s := NewSphere()
+	s.Material.Ambient = 1
+	s.Material.Diffuse = 0
+	s.Material.Specular = 0
+	w.Objects = append(w.Objects, s)
+
+	return w
+}
+
+func renderSamples(c Camera, w World, opts RenderOptions) (*Canvas, int) {
+	var samples int
+
+	opts.Progress = func(p RenderProgress) {
+		samples = p.Samples
+	}
+
+	image, _ := RenderContext(context.Background(), c, w, opts)
+
+	return image, samples
+}
+
+func TestSampleContrast(t *testing.T) {
+	colors := []Color{NewColor(0.2, 0.5, 0.1), NewColor(0.3, 0.1, 0.1), NewColor(0.25, 0.3, 0.1)}
+
+	assert.InDelta(t, 0.4, SampleContrast(colors), float64EqualityThreshold)
+	assert.InDelta(t, 0.0, SampleContrast(colors[:1]), float64EqualityThreshold)
+}
+
+func TestAdaptiveSamplingOfFlatRegionSharesCornerSamples(t *testing.T) {
+	c := NewCamera(8, 6, math.Pi/3)
+	opts := DefaultRenderOptions()
+	opts.Adaptive = true
+	opts.TileSize = 8
+
+	_, samples := renderSamples(c, NewWorld(), opts)
+
+	assert.Equal(t, 9*7+8*6, samples)
+}
+
+func TestAdaptiveSamplingRefinesEdges(t *testing.T) {
+	w := flatSphereWorld()
+	c := NewCamera(21, 21, math.Pi/3)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	opts := DefaultRenderOptions()
+	opts.Adaptive = true
+	opts.TileSize = 21
+	adaptive, adaptiveSamples := renderSamples(c, w, opts)
+
+	c.Samples = 64
+	reference, referenceSamples := renderSamples(c, w, DefaultRenderOptions())
+
+	assert.True(t, adaptiveSamples > 22*22+21*21)
+	assert.True(t, adaptiveSamples < referenceSamples/4)
+
+	for i := range adaptive.Pixels {
+		assert.InDelta(t, reference.Pixels[i].R, adaptive.Pixels[i].R, 0.15)
+	}
+}
+
+func TestAdaptiveSamplingRespectsMaxSamples(t *testing.T) {
+	w := flatSphereWorld()
+	c := NewCamera(21, 21, math.Pi/3)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	opts := DefaultRenderOptions()
+	opts.Adaptive = true
+	opts.TileSize = 1
+	opts.AdaptiveDepth = 6
+	opts.MaxSamples = 9
+
+	_, samples := renderSamples(c, w, opts)
+
+	assert.True(t, samples <= 21*21*opts.MaxSamples)
+
+	opts.MaxSamples = 13
+	_, refined := renderSamples(c, w, opts)
+
+	assert.True(t, refined > samples)
+	assert.True(t, refined <= 21*21*opts.MaxSamples)
+}
+
+func TestAdaptiveSamplingRejectsIgnoredCameraSettings(t *testing.T) {
+	c := NewCamera(4, 4, math.Pi/3)
+	opts := DefaultRenderOptions()
+	opts.Adaptive = true
+
+	_, err := RenderContext(context.Background(), c, NewWorld(), opts)
+	assert.Nil(t, err)
+
+	for _, configure := range []func(c *Camera){
+		func(c *Camera) { c.Samples = 4 },
+		func(c *Camera) { c.Sampling = SampleJittered },
+		func(c *Camera) { c.Filter = FilterMitchell },
+		func(c *Camera) { c.FilterRadius = 2 },
+	} {
+		configured := c
+		configure(&configured)
+
+		_, err := RenderContext(context.Background(), configured, NewWorld(), opts)
+		assert.NotNil(t, err)
+	}
+
+	opts.MaxSamples = 4
+	_, err = RenderContext(context.Background(), c, NewWorld(), opts)
+	assert.NotNil(t, err)
+}
+
+func TestNonAdaptiveRenderCountsSamples(t *testing.T) {
+	c := NewCamera(10, 10, math.Pi/3)
+	c.Samples = 4
+
+	_, samples := renderSamples(c, NewWorld(), DefaultRenderOptions())
+
+	assert.Equal(t, 400, samples)
+}
@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+type Interpolation int
+
+const (
+	InterpolateLinear Interpolation = iota
+	InterpolateStep
+	InterpolateBezier
+)
+
+var interpolations = []Interpolation{InterpolateLinear, InterpolateStep, InterpolateBezier}
+
+var EaseInOut = [4]float64{0.42, 0, 0.58, 1}
+
+func (i Interpolation) String() string {
+	return [...]string{"linear", "step", "bezier"}[i]
+}
+
+func ParseInterpolation(name string) (Interpolation, error) {
+	for _, i := range interpolations {
+		if i.String() == name {
+			return i, nil
+		}
+	}
+
+	return InterpolateLinear, fmt.Errorf("unknown interpolation %q", name)
+}
+
+type Keyframe struct {
+	Time          float64
+	Value         []float64
+	Interpolation Interpolation
+	Handles       [4]float64
+}
+
+func (k Keyframe) Ease(u float64) float64 {
+	u = math.Min(math.Max(u, 0), 1)
+
+	switch k.Interpolation {
+	case InterpolateStep:
+		return 0
+	case InterpolateBezier:
+		return CubicBezierEase(k.Handles, u)
+	default:
+		return u
+	}
+}
+
+type Track struct {
+	Keyframes []Keyframe
+}
+
+func NewTrack(keyframes ...Keyframe) (Track, error) {
+	if len(keyframes) == 0 {
+		return Track{}, fmt.Errorf("a track needs at least one keyframe")
+	}
+
+	for i, k := range keyframes {
+		if k.Interpolation == InterpolateBezier && (k.Handles[0] < 0 || k.Handles[0] > 1 || k.Handles[2] < 0 || k.Handles[2] > 1) {
+			return Track{}, fmt.Errorf("keyframe %d bezier handles must have x between 0 and 1", i)
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		previous := keyframes[i-1]
+
+		if k.Time <= previous.Time {
+			return Track{}, fmt.Errorf("keyframe %d time %g must be after %g", i, k.Time, previous.Time)
+		}
+
+		if previous.Interpolation != InterpolateStep && len(k.Value) != len(previous.Value) {
+			return Track{}, fmt.Errorf("keyframe %d has %d values, the previous one has %d", i, len(k.Value), len(previous.Value))
+		}
+	}
+
+	return Track{Keyframes: append([]Keyframe(nil), keyframes...)}, nil
+}
+
+func (t Track) KeyframeAt(time float64) int {
+	i := sort.Search(len(t.Keyframes), func(i int) bool {
+		return t.Keyframes[i].Time > time
+	})
+
+	return maxInt(i-1, 0)
+}
+
+func (t Track) ValueAt(time float64) []float64 {
+	i := t.KeyframeAt(time)
+	a := t.Keyframes[i]
+
+	if i == len(t.Keyframes)-1 || time <= a.Time || a.Interpolation == InterpolateStep {
+		return append([]float64(nil), a.Value...)
+	}
+
+	b := t.Keyframes[i+1]
+	u := a.Ease((time - a.Time) / (b.Time - a.Time))
+	values := make([]float64, len(a.Value))
+
+	for j := range values {
+		values[j] = a.Value[j] + u*(b.Value[j]-a.Value[j])
+	}
+
+	return values
+}
+
+func (t Track) Duration() float64 {
+	return t.Keyframes[len(t.Keyframes)-1].Time
+}
+
+func CubicBezierEase(handles [4]float64, u float64) float64 {
+	lo, hi := 0.0, 1.0
+	s := u
+
+	for i := 0; i < 64; i++ {
+		x := cubicBezier(handles[0], handles[2], s)
+
+		if math.Abs(x-u) < 1e-9 {
+			break
+		}
+
+		if x < u {
+			lo = s
+		} else {
+			hi = s
+		}
+
+		s = (lo + hi) / 2
+	}
+
+	return cubicBezier(handles[1], handles[3], s)
+}
+
+func cubicBezier(p1, p2, s float64) float64 {
+	r := 1 - s
+
+	return 3*r*r*s*p1 + 3*r*s*s*p2 + s*s*s
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackInterpolatesLinearly(t *testing.T) {
+	track, err := NewTrack(
+		Keyframe{Time: 1, Value: []float64{0, 10}},
+		Keyframe{Time: 3, Value: []float64{4, 0}},
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{0, 10}, track.ValueAt(0))
+	assert.Equal(t, []float64{0, 10}, track.ValueAt(1))
+	assert.Equal(t, []float64{2, 5}, track.ValueAt(2))
+	assert.Equal(t, []float64{4, 0}, track.ValueAt(3))
+	assert.Equal(t, []float64{4, 0}, track.ValueAt(9))
+	assert.Equal(t, 3.0, track.Duration())
+}
+
+func TestTrackStepHoldsValue(t *testing.T) {
+	track, _ := NewTrack(
+		Keyframe{Time: 0, Value: []float64{1}, Interpolation: InterpolateStep},
+		Keyframe{Time: 1, Value: []float64{2, 3}},
+	)
+
+	assert.Equal(t, 0, track.KeyframeAt(0.99))
+	assert.Equal(t, []float64{1}, track.ValueAt(0.99))
+	assert.Equal(t, 1, track.KeyframeAt(1))
+	assert.Equal(t, []float64{2, 3}, track.ValueAt(1))
+}
+
+func TestTrackBezierEases(t *testing.T) {
+	track, _ := NewTrack(
+		Keyframe{Time: 0, Value: []float64{0}, Interpolation: InterpolateBezier, Handles: EaseInOut},
+		Keyframe{Time: 1, Value: []float64{1}},
+	)
+
+	assert.InDelta(t, 0.5, track.ValueAt(0.5)[0], 1e-6)
+	assert.Less(t, track.ValueAt(0.1)[0], 0.1)
+	assert.Greater(t, track.ValueAt(0.9)[0], 0.9)
+}
+
+func TestCubicBezierEase(t *testing.T) {
+	linear := [4]float64{0.25, 0.25, 0.75, 0.75}
+
+	for _, u := range []float64{0, 0.2, 0.5, 0.8, 1} {
+		assert.InDelta(t, u, CubicBezierEase(linear, u), 1e-6)
+	}
+
+	assert.InDelta(t, 0, CubicBezierEase(EaseInOut, 0), 1e-9)
+	assert.InDelta(t, 1, CubicBezierEase(EaseInOut, 1), 1e-9)
+}
+
+func TestNewTrackValidatesKeyframes(t *testing.T) {
+	_, err := NewTrack()
+	assert.NotNil(t, err)
+
+	_, err = NewTrack(Keyframe{Time: 1, Value: []float64{0}}, Keyframe{Time: 1, Value: []float64{1}})
+	assert.NotNil(t, err)
+
+	_, err = NewTrack(Keyframe{Time: 0, Value: []float64{0}}, Keyframe{Time: 1, Value: []float64{1, 2}})
+	assert.NotNil(t, err)
+
+	_, err = NewTrack(Keyframe{Time: 0, Value: []float64{0}, Interpolation: InterpolateBezier, Handles: [4]float64{1.5, 0, 0.5, 1}})
+	assert.NotNil(t, err)
+}
+
+func TestParseInterpolation(t *testing.T) {
+	for _, i := range interpolations {
+		parsed, err := ParseInterpolation(i.String())
+		assert.Nil(t, err)
+		assert.Equal(t, i, parsed)
+	}
+
+	_, err := ParseInterpolation("cubic")
+	assert.NotNil(t, err)
+}
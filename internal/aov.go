@@ -0,0 +1,203 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+type AOV int
+
+const (
+	AOVDepth AOV = iota
+	AOVNormal
+	AOVObjectID
+	AOVAlbedo
+	AOVDirect
+	AOVIndirect
+	AOVReflection
+	AOVRefraction
+	AOVLights
+)
+
+var allAOVs = []AOV{AOVDepth, AOVNormal, AOVObjectID, AOVAlbedo, AOVDirect, AOVIndirect, AOVReflection, AOVRefraction, AOVLights}
+
+func (a AOV) String() string {
+	return [...]string{"depth", "normal", "object-id", "albedo", "direct", "indirect", "reflection", "refraction", "lights"}[a]
+}
+
+func ParseAOV(name string) (AOV, error) {
+	for _, a := range allAOVs {
+		if a.String() == name {
+			return a, nil
+		}
+	}
+
+	return AOVDepth, fmt.Errorf("unknown AOV %q", name)
+}
+
+func (a AOV) Lighting() bool {
+	return a >= AOVDirect
+}
+
+func AllAOVs() []AOV {
+	return append([]AOV(nil), allAOVs...)
+}
+
+type Passes map[string]*Canvas
+
+func PassNames(aovs []AOV, w World) []string {
+	var names []string
+
+	for _, a := range aovs {
+		if a != AOVLights {
+			names = append(names, a.String())
+			continue
+		}
+
+		for i := range w.Lights {
+			names = append(names, fmt.Sprintf("light-%d", i))
+		}
+	}
+
+	return names
+}
+
+func SamplePasses(w World, r Ray, depth int, aovs []AOV) []Color {
+	xs := IntersectWorld(w, r)
+	hit := Hit(xs)
+
+	if hit == (Intersection{}) {
+		return make([]Color, len(PassNames(aovs, w)))
+	}
+
+	comps := PrepareComputations(hit, r, xs)
+	var parts shading
+
+	if anyLighting(aovs) {
+		transmittance, scattered := mediaTransport(w, r, xs, hit.T)
+		parts = attenuateShading(shadeParts(w, comps, depth), transmittance, scattered)
+	}
+
+	values := make([]Color, 0, len(aovs)+len(w.Lights))
+
+	for _, a := range aovs {
+		switch a {
+		case AOVDepth:
+			d := hit.T * Magnitude(r.Direction)
+			values = append(values, NewColor(d, d, d))
+
+		case AOVNormal:
+			n := comps.NormalV
+			values = append(values, NewColor((n.X+1)/2, (n.Y+1)/2, (n.Z+1)/2))
+
+		case AOVObjectID:
+			values = append(values, ObjectIDColor(comps.Object.GetID()))
+
+		case AOVAlbedo:
+			values = append(values, surfaceColor(comps.Object.GetMaterial(), comps.Object, comps.Point, comps.Time))
+
+		case AOVDirect:
+			values = append(values, parts.direct)
+
+		case AOVIndirect:
+			values = append(values, AddColors(parts.reflected, parts.refracted))
+
+		case AOVReflection:
+			values = append(values, parts.reflected)
+
+		case AOVRefraction:
+			values = append(values, parts.refracted)
+
+		case AOVLights:
+			values = append(values, parts.lights...)
+		}
+	}
+
+	return values
+}
+
+func anyLighting(aovs []AOV) bool {
+	for _, a := range aovs {
+		if a.Lighting() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func attenuateShading(parts shading, transmittance, scattered Color) shading {
+	lights := make([]Color, len(parts.lights))
+
+	for i, light := range parts.lights {
+		lights[i] = HadamardProduct(light, transmittance)
+	}
+
+	return shading{
+		lights:    lights,
+		direct:    AddColors(HadamardProduct(parts.direct, transmittance), scattered),
+		reflected: HadamardProduct(parts.reflected, transmittance),
+		refracted: HadamardProduct(parts.refracted, transmittance),
+	}
+}
+
+func ObjectIDColor(id int64) Color {
+	h := mix64(uint64(id))
+
+	return NewColor(
+		float64(h&0xff)/255,
+		float64((h>>8)&0xff)/255,
+		float64((h>>16)&0xff)/255,
+	)
+}
+
+func NormalizePass(c *Canvas) *Canvas {
+	peak := 0.0
+
+	for _, p := range c.Pixels {
+		peak = math.Max(peak, maxComponent(p))
+	}
+
+	result := NewCanvas(c.W, c.H)
+
+	if peak <= 0 {
+		return result
+	}
+
+	for i, p := range c.Pixels {
+		result.Pixels[i] = ColorScalarDivide(p, peak)
+	}
+
+	return result
+}
+
+func pixelPasses(c Camera, w World, px, py int, opts RenderOptions, rng *rand.Rand) []Color {
+	samples := PixelSamples(c, rng)
+	values := make([][]Color, len(samples))
+
+	for i, s := range samples {
+		r, ok := CameraRay(c, px, py, s)
+
+		if !ok {
+			values[i] = make([]Color, len(PassNames(opts.AOVs, w)))
+			continue
+		}
+
+		w.Time = r.Time
+		values[i] = SamplePasses(w, r, opts.Depth, opts.AOVs)
+	}
+
+	result := make([]Color, len(values[0]))
+	colors := make([]Color, len(samples))
+
+	for p := range result {
+		for i := range samples {
+			colors[i] = values[i][p]
+		}
+
+		result[p] = FilterSamples(c, samples, colors)
+	}
+
+	return result
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAOV(t *testing.T) {
+	for _, a := range AllAOVs() {
+		parsed, err := ParseAOV(a.String())
+		assert.Nil(t, err)
+		assert.Equal(t, a, parsed)
+	}
+
+	_, err := ParseAOV("motion")
+	assert.NotNil(t, err)
+
+	assert.False(t, AOVAlbedo.Lighting())
+	assert.True(t, AOVDirect.Lighting())
+	assert.True(t, AOVLights.Lighting())
+}
+
+func TestPassNamesExpandPerLight(t *testing.T) {
+	w := NewDefaultWorld()
+	w.Lights = append(w.Lights, NewPointLight(NewPoint(10, 10, -10), NewColor(1, 1, 1)))
+
+	names := PassNames([]AOV{AOVDepth, AOVLights, AOVAlbedo}, w)
+
+	assert.Equal(t, []string{"depth", "light-0", "light-1", "albedo"}, names)
+}
+
+func TestSamplePassesOnHit(t *testing.T) {
+	w := NewDefaultWorld()
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+
+	values := SamplePasses(w, r, RecursionDepth, AllAOVs())
+	beauty := ColorAt(w, r, RecursionDepth)
+
+	assert.Equal(t, len(PassNames(AllAOVs(), w)), len(values))
+	assert.True(t, ColorEquals(NewColor(4, 4, 4), values[0]))
+	assert.True(t, ColorEquals(NewColor(0.5, 0.5, 0), values[1]))
+	assert.True(t, ColorEquals(ObjectIDColor(w.Objects[0].GetID()), values[2]))
+	assert.True(t, ColorEquals(NewColor(0.8, 1.0, 0.6), values[3]))
+	assert.True(t, ColorEquals(beauty, AddColors(values[4], values[5])))
+	assert.True(t, ColorEquals(values[4], values[8]))
+}
+
+func TestSamplePassesSplitsReflectionAndRefraction(t *testing.T) {
+	w := NewDefaultWorld()
+
+	floor := NewPlane()
+	floor.SetTransform(Translate(0, -1, 0))
+	floor.Material.Reflective = 0.5
+	floor.Material.Transparency = 0.5
+	floor.Material.RefractiveIndex = 1.5
+	w.Objects = append(w.Objects, floor)
+
+	ball := NewSphere()
+	ball.SetTransform(Translate(0, -3.5, -0.5))
+	ball.Material.SetColor(NewColor(1, 0, 0))
+	ball.Material.Ambient = 0.5
+	w.Objects = append(w.Objects, ball)
+
+	r := NewRay(NewPoint(0, 0, -3), NewVector(0, -math.Sqrt(2)/2, math.Sqrt(2)/2))
+	values := SamplePasses(w, r, RecursionDepth, []AOV{AOVDirect, AOVReflection, AOVRefraction, AOVIndirect})
+	beauty := ColorAt(w, r, RecursionDepth)
+
+	assert.True(t, values[1].R > 0)
+	assert.True(t, values[2].R > 0)
+	assert.True(t, ColorEquals(AddColors(values[1], values[2]), values[3]))
+	assert.True(t, ColorEquals(beauty, AddColors(values[0], values[3])))
+}
+
+func TestSamplePassesSumToBeautyThroughMedia(t *testing.T) {
+	w := NewDefaultWorld()
+	w.Medium = NewMedium(NewColor(0.1, 0.2, 0.3), NewColor(0, 0, 0), 0)
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+
+	values := SamplePasses(w, r, RecursionDepth, []AOV{AOVDirect, AOVIndirect, AOVLights})
+	beauty := ColorAt(w, r, RecursionDepth)
+
+	assert.True(t, ColorEquals(beauty, AddColors(values[0], values[1])))
+	assert.True(t, ColorEquals(values[0], values[2]))
+}
+
+func TestSamplePassesOnMissAreBlack(t *testing.T) {
+	w := NewDefaultWorld()
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 1, 0))
+
+	for _, value := range SamplePasses(w, r, RecursionDepth, AllAOVs()) {
+		assert.True(t, ColorEquals(black, value))
+	}
+}
+
+func TestRenderPassesProducesBuffers(t *testing.T) {
+	w := NewDefaultWorld()
+	c := NewCamera(11, 11, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	opts := DefaultRenderOptions()
+	opts.AOVs = []AOV{AOVAlbedo, AOVLights}
+	image, passes, err := RenderPasses(context.Background(), c, w, opts)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(passes))
+	assert.True(t, ColorEquals(NewColor(0.8, 1.0, 0.6), passes["albedo"].GetColorAtPixel(5, 5)))
+	assert.True(t, ColorEquals(image.GetColorAtPixel(5, 5), passes["light-0"].GetColorAtPixel(5, 5)))
+}
+
+func TestNormalizePassScalesToPeak(t *testing.T) {
+	c := NewCanvas(2, 1)
+	c.WritePixelAtCoord(0, 0, NewColor(2, 2, 2))
+	c.WritePixelAtCoord(1, 0, NewColor(4, 4, 4))
+
+	normalized := NormalizePass(c)
+
+	assert.True(t, ColorEquals(NewColor(0.5, 0.5, 0.5), normalized.GetColorAtPixel(0, 0)))
+	assert.True(t, ColorEquals(NewColor(1, 1, 1), normalized.GetColorAtPixel(1, 0)))
+}
+
+func TestAOVsDoNotChangeBeautyNoise(t *testing.T) {
+	c, w := seededTestScene()
+
+	opts := DefaultRenderOptions()
+	opts.Seed = 3
+	plain := RenderWithOptions(c, w, opts)
+
+	opts.AOVs = []AOV{AOVDirect, AOVLights}
+	image, passes, err := RenderPasses(context.Background(), c, w, opts)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(passes))
+	assert.Equal(t, plain.Pixels, image.Pixels)
+}
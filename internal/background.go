@@ -0,0 +1,59 @@
+package internal
+
+type Background interface {
+	ColorFor(direction Tuple) Color
+}
+
+type SolidBackground struct {
+	Color Color
+}
+
+func NewSolidBackground(color Color) SolidBackground {
+	return SolidBackground{Color: color}
+}
+
+func (b SolidBackground) ColorFor(direction Tuple) Color {
+	return b.Color
+}
+
+type GradientBackground struct {
+	Bottom Color
+	Top    Color
+}
+
+func NewGradientBackground(bottom, top Color) GradientBackground {
+	return GradientBackground{Bottom: bottom, Top: top}
+}
+
+func (b GradientBackground) ColorFor(direction Tuple) Color {
+	t := (Normalize(direction).Y + 1) / 2
+
+	return AddColors(ColorScalarMultiply(b.Bottom, 1-t), ColorScalarMultiply(b.Top, t))
+}
+
+type PatternBackground struct {
+	Pattern Pattern
+}
+
+func NewPatternBackground(pattern Pattern) PatternBackground {
+	return PatternBackground{Pattern: pattern}
+}
+
+func (b PatternBackground) ColorFor(direction Tuple) Color {
+	d := Normalize(direction)
+	point := MatrixTupleMultiply(b.Pattern.GetInverse(), NewPoint(d.X, d.Y, d.Z))
+
+	return b.Pattern.PatternAt(point)
+}
+
+func (w World) BackgroundColor(r Ray) Color {
+	if w.Environment != nil {
+		return w.Environment.Radiance(r.Direction)
+	}
+
+	if w.Background == nil {
+		return black
+	}
+
+	return w.Background.ColorFor(r.Direction)
+}
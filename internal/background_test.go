@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorldWithoutBackgroundIsBlack(t *testing.T) {
+	w := NewDefaultWorld()
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 1, 0))
+
+	assert.True(t, ColorEquals(black, ColorAt(w, r, RecursionDepth)))
+}
+
+func TestSolidBackgroundOnMiss(t *testing.T) {
+	w := NewDefaultWorld()
+	w.Background = NewSolidBackground(NewColor(0.2, 0.3, 0.4))
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 1, 0))
+
+	assert.True(t, ColorEquals(NewColor(0.2, 0.3, 0.4), ColorAt(w, r, RecursionDepth)))
+}
+
+func TestGradientBackgroundBlendsVertically(t *testing.T) {
+	b := NewGradientBackground(NewColor(1, 1, 1), NewColor(0, 0, 1))
+
+	assert.True(t, ColorEquals(NewColor(0, 0, 1), b.ColorFor(NewVector(0, 1, 0))))
+	assert.True(t, ColorEquals(NewColor(1, 1, 1), b.ColorFor(NewVector(0, -3, 0))))
+	assert.True(t, ColorEquals(NewColor(0.5, 0.5, 1), b.ColorFor(NewVector(1, 0, 0))))
+}
+
+func TestPatternBackgroundUsesRayDirection(t *testing.T) {
+	b := NewPatternBackground(NewStripePattern(white, black))
+
+	assert.True(t, ColorEquals(white, b.ColorFor(NewVector(0.5, 0, 1))))
+	assert.True(t, ColorEquals(black, b.ColorFor(NewVector(-0.5, 0, 1))))
+}
+
+func TestReflectionsPickUpBackground(t *testing.T) {
+	w := NewWorld()
+	w.Background = NewSolidBackground(NewColor(0, 0, 1))
+
+	mirror := NewPlane()
+	mirror.Material.SetColor(black)
+	mirror.Material.Ambient = 0
+	mirror.Material.Diffuse = 0
+	mirror.Material.Specular = 0
+	mirror.Material.Reflective = 1
+	w.Objects = append(w.Objects, mirror)
+
+	r := NewRay(NewPoint(0, 1, -1), Normalize(NewVector(0, -1, 1)))
+
+	assert.True(t, ColorEquals(NewColor(0, 0, 1), ColorAt(w, r, RecursionDepth)))
+}
+
+func TestTransparentBackgroundWritesAlpha(t *testing.T) {
+	w := NewDefaultWorld()
+	w.Background = NewSolidBackground(NewColor(0, 0, 1))
+	c := NewCamera(11, 11, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	opts := DefaultRenderOptions()
+	opts.TransparentBackground = true
+	image := RenderWithOptions(c, w, opts)
+
+	assert.Equal(t, 1.0, image.GetAlphaAtPixel(5, 5))
+	assert.Equal(t, 0.0, image.GetAlphaAtPixel(0, 0))
+	assert.True(t, ColorEquals(black, image.GetColorAtPixel(0, 0)))
+
+	opts.Adaptive = true
+	adaptive := RenderWithOptions(c, w, opts)
+
+	assert.Equal(t, 0.0, adaptive.GetAlphaAtPixel(0, 0))
+	assert.Equal(t, 1.0, adaptive.GetAlphaAtPixel(5, 5))
+}
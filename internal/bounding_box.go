@@ -77,6 +77,8 @@ func BoundsOf(shape Shape) BoundingBox {
 		}
 
 		return box
+	case *Volume:
+		return temp.Bounds
 	case *CSG:
 		box := NewEmptyBoundingBox()
 		box.AddBox(ParentSpaceBoundsOf(temp.Left))
@@ -108,5 +110,9 @@ func TransformBox(box BoundingBox, transform Matrix) BoundingBox {
 }
 
 func ParentSpaceBoundsOf(shape Shape) BoundingBox {
+	if m := shape.GetMotion(); m != nil {
+		return m.Bounds(BoundsOf(shape))
+	}
+
 	return TransformBox(BoundsOf(shape), shape.GetTransform())
 }
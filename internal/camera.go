@@ -1,6 +1,13 @@
 package internal
 
-import "math"
+import (
+	"context"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+)
 
 type Camera struct {
 	Hsize      int
@@ -10,14 +17,40 @@ type Camera struct {
 	FOV        float64
 	PixelSize  float64
 	Transform  Matrix
+	Projection Projection
+	ViewWidth  float64
+	ShiftX     float64
+
+	Samples      int
+	Sampling     SamplePattern
+	Filter       PixelFilter
+	FilterRadius float64
+
+	Aperture      float64
+	FocalDistance float64
+	Blades        int
+	BladeRotation float64
+
+	ShutterOpen  float64
+	ShutterClose float64
 }
 
 func NewCamera(hsize, vsize int, fov float64) Camera {
+	c := Camera{Transform: NewIdentity4()}
+	c.SetSize(hsize, vsize, fov)
+
+	return c
+}
+
+func (c *Camera) SetSize(hsize, vsize int, fov float64) {
 	var halfWidth, halfHeight float64
 	halfView := math.Tan(fov / 2)
 	aspect := float64(hsize) / float64(vsize)
 
-	if aspect >= 1 {
+	if c.Projection == ProjectionOrthographic {
+		halfWidth = c.ViewWidth / 2
+		halfHeight = halfWidth / aspect
+	} else if aspect >= 1 {
 		halfWidth = halfView
 		halfHeight = halfView / aspect
 	} else {
@@ -25,44 +58,409 @@ func NewCamera(hsize, vsize int, fov float64) Camera {
 		halfHeight = halfView
 	}
 
-	pixelSize := (halfWidth * 2) / float64(hsize)
+	c.Hsize = hsize
+	c.Vsize = vsize
+	c.HalfWidth = halfWidth
+	c.HalfHeight = halfHeight
+	c.FOV = fov
+	c.PixelSize = (halfWidth * 2) / float64(hsize)
+}
 
-	return Camera{
-		Hsize:      hsize,
-		Vsize:      vsize,
-		HalfWidth:  halfWidth,
-		HalfHeight: halfHeight,
-		FOV:        fov,
-		PixelSize:  pixelSize,
-		Transform:  NewIdentity4(),
+func (c Camera) filterRadius() float64 {
+	if c.FilterRadius > 0 {
+		return c.FilterRadius
 	}
+
+	return c.Filter.DefaultRadius()
 }
 
 func RayForPixel(c Camera, px, py int) Ray {
-	xOffset := (float64(px) + 0.5) * c.PixelSize
-	yOffset := (float64(py) + 0.5) * c.PixelSize
+	return RayForSample(c, px, py, PixelCenter)
+}
+
+func RayForSample(c Camera, px, py int, s CameraSample) Ray {
+	r, _ := CameraRay(c, px, py, s)
+
+	return r
+}
+
+func CameraRay(c Camera, px, py int, s CameraSample) (Ray, bool) {
+	r, ok := projectRay(c, px, py, s)
+	r.Time = c.shutterTime(s.Time)
+
+	return r, ok
+}
+
+func projectRay(c Camera, px, py int, s CameraSample) (Ray, bool) {
+	if c.Projection.IsPanoramic() {
+		return panoramicRay(c, float64(px)+s.X, float64(py)+s.Y)
+	}
+
+	xOffset := (float64(px) + s.X) * c.PixelSize
+	yOffset := (float64(py) + s.Y) * c.PixelSize
 
 	worldX := c.HalfWidth - xOffset
 	worldY := c.HalfHeight - yOffset
 
 	transformInverse := MatrixInverse(c.Transform)
-	pixel := MatrixTupleMultiply(transformInverse, NewPoint(worldX, worldY, -1))
+
+	if c.Projection == ProjectionOrthographic {
+		return orthographicRay(worldX, worldY, transformInverse), true
+	}
+
+	if c.HasLens() {
+		origin, direction := thinLensRay(c, NewPoint(worldX+c.ShiftX, worldY, -1), s)
+
+		return NewRay(MatrixTupleMultiply(transformInverse, origin), Normalize(MatrixTupleMultiply(transformInverse, direction))), true
+	}
+
+	pixel := MatrixTupleMultiply(transformInverse, NewPoint(worldX+c.ShiftX, worldY, -1))
 	origin := MatrixTupleMultiply(transformInverse, NewPoint(0, 0, 0))
 	direction := Normalize(SubTuples(pixel, origin))
 
-	return NewRay(origin, direction)
+	return NewRay(origin, direction), true
+}
+
+type RenderOptions struct {
+	Depth    int
+	Workers  int
+	TileSize int
+	Progress func(RenderProgress)
+
+	Adaptive          bool
+	AdaptiveThreshold float64
+	AdaptiveDepth     int
+	MaxSamples        int
+
+	Integrator    Integrator
+	MaxBounces    int
+	RouletteStart int
+
+	AOVs []AOV
+
+	TransparentBackground bool
+
+	Seed int64
+
+	Photons      int
+	PhotonRadius float64
+
+	Spectral    bool
+	Wavelengths int
+}
+
+type RenderProgress struct {
+	TilesDone   int
+	TilesTotal  int
+	PixelsDone  int
+	PixelsTotal int
+	Samples     int
+	Elapsed     time.Duration
+	ETA         time.Duration
+}
+
+func (p RenderProgress) Fraction() float64 {
+	if p.PixelsTotal == 0 {
+		return 1
+	}
+
+	return float64(p.PixelsDone) / float64(p.PixelsTotal)
+}
+
+func DefaultRenderOptions() RenderOptions {
+	return RenderOptions{
+		Depth:    RecursionDepth,
+		Workers:  runtime.NumCPU(),
+		TileSize: 16,
+
+		AdaptiveThreshold: 0.1,
+		AdaptiveDepth:     3,
+		MaxSamples:        64,
+
+		MaxBounces:    16,
+		RouletteStart: 3,
+
+		PhotonRadius: 0.1,
+
+		Wavelengths: 8,
+	}
+}
+
+type Tile struct {
+	X0, Y0 int
+	X1, Y1 int
+}
+
+func (t Tile) Pixels() int {
+	return (t.X1 - t.X0) * (t.Y1 - t.Y0)
+}
+
+type tileResult struct {
+	Tile    Tile
+	Samples int
+}
+
+func SplitTiles(width, height, size int) []Tile {
+	var tiles []Tile
+
+	if size < 1 {
+		size = 1
+	}
+
+	for y := 0; y < height; y += size {
+		for x := 0; x < width; x += size {
+			tiles = append(tiles, Tile{
+				X0: x,
+				Y0: y,
+				X1: minInt(x+size, width),
+				Y1: minInt(y+size, height),
+			})
+		}
+	}
+
+	return tiles
 }
 
 func Render(c Camera, w World) *Canvas {
+	return RenderWithOptions(c, w, DefaultRenderOptions())
+}
+
+func RenderWithOptions(c Camera, w World, opts RenderOptions) *Canvas {
+	image, _ := RenderContext(context.Background(), c, w, opts)
+	return image
+}
+
+func RenderContext(ctx context.Context, c Camera, w World, opts RenderOptions) (*Canvas, error) {
+	image, _, err := RenderPasses(ctx, c, w, opts)
+	return image, err
+}
+
+func RenderPasses(ctx context.Context, c Camera, w World, opts RenderOptions) (*Canvas, Passes, error) {
+	start := time.Now()
 	image := NewCanvas(c.Hsize, c.Vsize)
 
-	for y := 0; y < c.Vsize-1; y++ {
-		for x := 0; x < c.Hsize-1; x++ {
-			ray := RayForPixel(c, x, y)
-			color := ColorAt(w, ray, RecursionDepth)
+	if opts.TransparentBackground {
+		image.EnableAlpha()
+	}
+
+	if err := validateAdaptive(c, opts); err != nil {
+		return image, nil, err
+	}
+
+	for _, obj := range w.Objects {
+		if err := validateVolumes(obj); err != nil {
+			return image, nil, err
+		}
+
+		cacheMediumOwners(obj)
+	}
+
+	if opts.Photons > 0 && w.Caustics == nil {
+		var err error
+
+		if w.Caustics, err = BuildCausticMap(ctx, w, opts.Photons, opts.PhotonRadius, rand.New(rand.NewSource(opts.Seed))); err != nil {
+			return image, nil, err
+		}
+	}
+
+	names := PassNames(opts.AOVs, w)
+	buffers := make([]*Canvas, len(names))
+	passes := make(Passes, len(names))
+
+	for i, name := range names {
+		buffers[i] = NewCanvas(c.Hsize, c.Vsize)
+		passes[name] = buffers[i]
+	}
+	tiles := SplitTiles(c.Hsize, c.Vsize, opts.TileSize)
+	workers := opts.Workers
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	if workers > len(tiles) {
+		workers = len(tiles)
+	}
+
+	queue := make(chan Tile)
+	finished := make(chan tileResult, len(tiles))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for t := range queue {
+				if samples, ok := renderTile(ctx, c, w, opts, t, image, buffers); ok {
+					finished <- tileResult{t, samples}
+				}
+			}
+		}()
+	}
+
+	progress := RenderProgress{
+		TilesTotal:  len(tiles),
+		PixelsTotal: c.Hsize * c.Vsize,
+	}
+
+	report := func(r tileResult) {
+		progress.TilesDone++
+		progress.PixelsDone += r.Tile.Pixels()
+		progress.Samples += r.Samples
+		progress.Elapsed = time.Since(start)
+		progress.ETA = estimateRemaining(progress)
+
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+	}
+
+	next := 0
+
+	for progress.TilesDone < len(tiles) {
+		var send chan Tile
+		var nextTile Tile
+
+		if next < len(tiles) {
+			send = queue
+			nextTile = tiles[next]
+		}
+
+		select {
+		case send <- nextTile:
+			next++
+
+		case r := <-finished:
+			report(r)
+
+		case <-ctx.Done():
+			close(queue)
+			wg.Wait()
+			close(finished)
+
+			for r := range finished {
+				report(r)
+			}
+
+			if progress.TilesDone < len(tiles) {
+				return image, passes, ctx.Err()
+			}
+
+			return image, passes, nil
+		}
+	}
+
+	close(queue)
+	wg.Wait()
+
+	return image, passes, nil
+}
+
+func estimateRemaining(p RenderProgress) time.Duration {
+	if p.PixelsDone == 0 {
+		return 0
+	}
+
+	perPixel := float64(p.Elapsed) / float64(p.PixelsDone)
+
+	return time.Duration(perPixel * float64(p.PixelsTotal-p.PixelsDone))
+}
+
+func renderTile(ctx context.Context, c Camera, w World, opts RenderOptions, t Tile, image *Canvas, buffers []*Canvas) (int, bool) {
+	rng := rand.New(rand.NewSource(tileSeed(opts.Seed, t)))
+	w = w.WithRand(rng)
+	samples := 0
+
+	var aovRng *rand.Rand
+
+	if len(buffers) > 0 {
+		aovRng = rand.New(rand.NewSource(int64(mix64(uint64(tileSeed(opts.Seed, t)) ^ aovSeedSalt))))
+	}
+
+	var adaptive *adaptiveSampler
+
+	if opts.Adaptive {
+		adaptive = newAdaptiveSampler(c, w, opts, rng)
+	}
+
+	for y := t.Y0; y < t.Y1; y++ {
+		if ctx.Err() != nil {
+			return samples, false
+		}
+
+		for x := t.X0; x < t.X1; x++ {
+			if len(buffers) > 0 {
+				for i, color := range pixelPasses(c, w.WithRand(aovRng), x, y, opts, aovRng) {
+					buffers[i].WritePixelAtCoord(x, y, color)
+				}
+			}
+
+			if adaptive != nil {
+				color, alpha := adaptive.PixelColor(x, y)
+				image.WritePixelAtCoord(x, y, color)
+				image.WriteAlphaAtCoord(x, y, alpha)
+				continue
+			}
+
+			color, alpha, n := PixelColorAlpha(c, w, x, y, opts, rng)
 			image.WritePixelAtCoord(x, y, color)
+			image.WriteAlphaAtCoord(x, y, alpha)
+			samples += n
 		}
 	}
 
-	return image
+	if adaptive != nil {
+		samples = adaptive.samples
+	}
+
+	return samples, true
+}
+
+const aovSeedSalt = 0x414f56
+
+func tileSeed(seed int64, t Tile) int64 {
+	return int64(mix64(uint64(seed) ^ mix64(uint64(t.X0)<<32|uint64(t.Y0))))
+}
+
+func PixelColor(c Camera, w World, px, py int, opts RenderOptions, rng *rand.Rand) (Color, int) {
+	color, _, n := PixelColorAlpha(c, w, px, py, opts, rng)
+	return color, n
+}
+
+func PixelColorAlpha(c Camera, w World, px, py int, opts RenderOptions, rng *rand.Rand) (Color, float64, int) {
+	integrator := opts.integrator()
+	samples := PixelSamples(c, rng)
+	colors := make([]Color, len(samples))
+	alphas := make([]Color, len(samples))
+
+	for i, s := range samples {
+		color, alpha := cameraRadiance(w, c, px, py, s, integrator, opts, rng)
+		colors[i] = color
+		alphas[i] = NewColor(alpha, alpha, alpha)
+	}
+
+	return FilterSamples(c, samples, colors), FilterSamples(c, samples, alphas).R, len(samples)
+}
+
+func cameraRadiance(w World, c Camera, px, py int, s CameraSample, integrator Integrator, opts RenderOptions, rng *rand.Rand) (Color, float64) {
+	r, ok := CameraRay(c, px, py, s)
+
+	if !ok {
+		return black, 0
+	}
+
+	w.Time = r.Time
+
+	if opts.TransparentBackground && Hit(IntersectWorld(w, r)) == (Intersection{}) {
+		return black, 0
+	}
+
+	if opts.Spectral {
+		return spectralRadiance(w, r, integrator, opts.Wavelengths, rng), 1
+	}
+
+	return integrator.Li(w, r, rng), 1
 }
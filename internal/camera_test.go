@@ -1,8 +1,10 @@
 package internal
 
 import (
+	"context"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -71,3 +73,168 @@ func TestRenderingWorldWithCamera(t *testing.T) {
 	assert.InDelta(t, 0.47583, pixelColor.G, float64EqualityThreshold)
 	assert.InDelta(t, 0.28550, pixelColor.B, float64EqualityThreshold)
 }
+
+func TestSplitTilesCoversCanvas(t *testing.T) {
+	tiles := SplitTiles(10, 7, 4)
+	covered := make(map[int]int)
+
+	for _, tile := range tiles {
+		for y := tile.Y0; y < tile.Y1; y++ {
+			for x := tile.X0; x < tile.X1; x++ {
+				covered[x+10*y]++
+			}
+		}
+	}
+
+	assert.Equal(t, 6, len(tiles))
+	assert.Equal(t, 70, len(covered))
+
+	for _, count := range covered {
+		assert.Equal(t, 1, count)
+	}
+}
+
+func TestParallelRenderMatchesSerialRender(t *testing.T) {
+	w := NewDefaultWorld()
+	w.Objects[1].SetMaterial(NewMaterial(NewColor(1, 1, 1), nil, 0.1, 0.9, 0.9, 200, 0.5, 0.5, 1.5))
+	c := NewCamera(37, 23, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	serialOpts := DefaultRenderOptions()
+	serialOpts.Workers = 1
+	serialOpts.TileSize = 37 * 23
+	serial := RenderWithOptions(c, w, serialOpts)
+
+	parallelOpts := DefaultRenderOptions()
+	parallelOpts.Workers = 8
+	parallelOpts.TileSize = 5
+	parallel := RenderWithOptions(c, w, parallelOpts)
+
+	assert.Equal(t, serial.Pixels, parallel.Pixels)
+}
+
+func TestRenderCoversLastRowAndColumn(t *testing.T) {
+	w := NewDefaultWorld()
+	c := NewCamera(11, 11, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 0, -1.1), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	image := Render(c, w)
+
+	assert.False(t, ColorEquals(NewColor(0, 0, 0), image.GetColorAtPixel(10, 10)))
+}
+
+func TestRenderReportsProgressForEveryTile(t *testing.T) {
+	w := NewDefaultWorld()
+	c := NewCamera(20, 10, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	var events []RenderProgress
+	opts := DefaultRenderOptions()
+	opts.TileSize = 5
+	opts.Workers = 3
+	opts.Progress = func(p RenderProgress) {
+		events = append(events, p)
+	}
+
+	_, err := RenderContext(context.Background(), c, w, opts)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 8, len(events))
+
+	last := events[len(events)-1]
+	assert.Equal(t, 8, last.TilesDone)
+	assert.Equal(t, 8, last.TilesTotal)
+	assert.Equal(t, 200, last.PixelsDone)
+	assert.Equal(t, 1.0, last.Fraction())
+	assert.Equal(t, time.Duration(0), last.ETA)
+
+	for i := 1; i < len(events); i++ {
+		assert.True(t, events[i].PixelsDone > events[i-1].PixelsDone)
+	}
+}
+
+func TestRenderStopsOnCancellation(t *testing.T) {
+	w := NewDefaultWorld()
+	c := NewCamera(40, 40, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := DefaultRenderOptions()
+	opts.TileSize = 4
+	opts.Workers = 1
+	opts.Progress = func(p RenderProgress) {
+		cancel()
+	}
+
+	image, err := RenderContext(ctx, c, w, opts)
+
+	assert.Equal(t, context.Canceled, err)
+	assert.NotNil(t, image)
+	assert.Equal(t, 40, image.W)
+}
+
+func TestRenderWithCancelledContextReturnsBlankCanvas(t *testing.T) {
+	w := NewDefaultWorld()
+	c := NewCamera(11, 11, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	image, err := RenderContext(ctx, c, w, DefaultRenderOptions())
+
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, ColorEquals(NewColor(0, 0, 0), image.GetColorAtPixel(5, 5)))
+}
+
+func seededTestScene() (Camera, World) {
+	w := NewDefaultWorld()
+	light := NewAreaLight(NewPoint(-1, 2, -4), NewVector(2, 0, 0), 4, NewVector(0, 2, 0), 4, NewColor(1, 1, 1))
+	light.Jitter = true
+	w.Lights = []LightSource{light}
+
+	floor := NewPlane()
+	floor.SetTransform(Translate(0, -1, 0))
+	w.Objects = append(w.Objects, floor)
+
+	c := NewCamera(16, 12, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 1, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+	c.Samples = 4
+	c.Sampling = SampleJittered
+
+	return c, w
+}
+
+func TestSeededRendersAreReproducible(t *testing.T) {
+	c, w := seededTestScene()
+
+	opts := DefaultRenderOptions()
+	opts.Seed = 7
+	opts.TileSize = 4
+	opts.Workers = 1
+	serial := RenderWithOptions(c, w, opts)
+
+	opts.Workers = 4
+	parallel := RenderWithOptions(c, w, opts)
+
+	opts.Integrator = PathIntegrator{MaxBounces: 4, RouletteStart: 2}
+	path1 := RenderWithOptions(c, w, opts)
+	path2 := RenderWithOptions(c, w, opts)
+
+	assert.Equal(t, serial.ToPPM(), parallel.ToPPM())
+	assert.Equal(t, serial.Pixels, parallel.Pixels)
+	assert.Equal(t, path1.Pixels, path2.Pixels)
+}
+
+func TestDifferentSeedsGiveDifferentNoise(t *testing.T) {
+	c, w := seededTestScene()
+
+	opts := DefaultRenderOptions()
+	opts.Seed = 1
+	first := RenderWithOptions(c, w, opts)
+
+	opts.Seed = 2
+	second := RenderWithOptions(c, w, opts)
+
+	assert.NotEqual(t, first.Pixels, second.Pixels)
+}
@@ -12,6 +12,7 @@ type Canvas struct {
 	W      int
 	H      int
 	Pixels []Color
+	Alpha  []float64
 }
 
 func NewCanvas(W, H int) *Canvas {
@@ -47,6 +48,34 @@ func (c *Canvas) WritePixelAtIndex(index int, col Color) {
 	c.Pixels[index] = col
 }
 
+func (c *Canvas) EnableAlpha() {
+	if c.Alpha != nil {
+		return
+	}
+
+	c.Alpha = make([]float64, len(c.Pixels))
+
+	for i := range c.Alpha {
+		c.Alpha[i] = 1
+	}
+}
+
+func (c *Canvas) WriteAlphaAtCoord(x, y int, alpha float64) {
+	if c.Alpha == nil || x < 0 || y < 0 || x >= c.W || y >= c.H {
+		return
+	}
+
+	c.Alpha[c.GetPixelIndex(x, y)] = alpha
+}
+
+func (c *Canvas) GetAlphaAtPixel(x, y int) float64 {
+	if c.Alpha == nil {
+		return 1
+	}
+
+	return c.Alpha[c.GetPixelIndex(x, y)]
+}
+
 func (c *Canvas) GetLastIndex() int {
 	return c.W*c.H - 1
 }
@@ -106,13 +135,27 @@ func formatPPM(colorValue float64, b *strings.Builder, writtenLen *int) {
 
 func (c *Canvas) ToPNG(image *image.RGBA) {
 	for i := 0; i < len(c.Pixels); i++ {
-		image.Pix[i*4] = clamp(c.Pixels[i].R)
-		image.Pix[i*4+1] = clamp(c.Pixels[i].G)
-		image.Pix[i*4+2] = clamp(c.Pixels[i].B)
-		image.Pix[i*4+3] = 255
+		alpha := uint8(255)
+
+		if c.Alpha != nil {
+			alpha = clamp(c.Alpha[i])
+		}
+
+		image.Pix[i*4] = minUint8(clamp(c.Pixels[i].R), alpha)
+		image.Pix[i*4+1] = minUint8(clamp(c.Pixels[i].G), alpha)
+		image.Pix[i*4+2] = minUint8(clamp(c.Pixels[i].B), alpha)
+		image.Pix[i*4+3] = alpha
 	}
 }
 
+func minUint8(a, b uint8) uint8 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
 func clamp(pixel float64) uint8 {
 	scaled := math.Ceil(pixel * 255.0)
 	scaled = math.Max(0.0, scaled)
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"fmt"
+	"image"
 	"strings"
 	"testing"
 
@@ -86,3 +87,16 @@ func TestNewlineSuffix(t *testing.T) {
 
 	assert.Equal(t, "\n", string(ppmString[len(ppmString)-1]))
 }
+
+func TestCanvasAlphaIsWrittenToPNG(t *testing.T) {
+	c := NewCanvas(2, 1)
+	c.WritePixelAtCoord(0, 0, NewColor(1, 1, 1))
+	c.WritePixelAtCoord(1, 0, NewColor(0.5, 0.5, 0.5))
+	c.EnableAlpha()
+	c.WriteAlphaAtCoord(1, 0, 0.25)
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	c.ToPNG(img)
+
+	assert.Equal(t, []uint8{255, 255, 255, 255, 64, 64, 64, 64}, img.Pix)
+}
@@ -12,19 +12,33 @@ type Computation struct {
 	Inside     bool
 	N1         float64
 	N2         float64
+	Thickness  float64
+	Wavelength float64
+	Time       float64
 }
 
 func NewComputation() Computation {
 	return Computation{}
 }
 
+func SpawnRay(comps Computation, origin, direction Tuple) Ray {
+	return Ray{
+		Origin:     origin,
+		Direction:  direction,
+		Wavelength: comps.Wavelength,
+		Time:       comps.Time,
+	}
+}
+
 func PrepareComputations(intersection Intersection, ray Ray, xs Intersections) Computation {
 	comps := NewComputation()
 	comps.T = intersection.T
+	comps.Wavelength = ray.Wavelength
+	comps.Time = ray.Time
 	comps.Object = intersection.Object
 	comps.Point = Position(ray, comps.T)
 	comps.EyeV = Negate(ray.Direction)
-	comps.NormalV = NormalAt(comps.Object, comps.Point, intersection)
+	comps.NormalV = NormalAtTime(comps.Object, comps.Point, intersection, comps.Time)
 	comps.ReflectV = Reflect(ray.Direction, comps.NormalV)
 
 	if Dot(comps.NormalV, comps.EyeV) < 0 {
@@ -46,7 +60,7 @@ func PrepareComputations(intersection Intersection, ray Ray, xs Intersections) C
 			if len(containers) == 0 {
 				comps.N1 = 1.0
 			} else {
-				comps.N1 = containers[len(containers)-1].GetMaterial().RefractiveIndex
+				comps.N1 = containers[len(containers)-1].GetMaterial().IndexAt(ray.Wavelength)
 			}
 		}
 
@@ -62,12 +76,26 @@ func PrepareComputations(intersection Intersection, ray Ray, xs Intersections) C
 			if len(containers) == 0 {
 				comps.N2 = 1.0
 			} else {
-				comps.N2 = containers[len(containers)-1].GetMaterial().RefractiveIndex
+				comps.N2 = containers[len(containers)-1].GetMaterial().IndexAt(ray.Wavelength)
 			}
 
 			break
 		}
 	}
 
+	if !comps.Inside {
+		comps.Thickness = exitDistance(intersection, ray, xs)
+	}
+
 	return comps
 }
+
+func exitDistance(hit Intersection, ray Ray, xs Intersections) float64 {
+	for _, i := range xs {
+		if i.Object == hit.Object && i.T > hit.T {
+			return (i.T - hit.T) * Magnitude(ray.Direction)
+		}
+	}
+
+	return 0
+}
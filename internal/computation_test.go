@@ -54,3 +54,15 @@ func TestUnderPointIsOffsetBelowSurface(t *testing.T) {
 	assert.Greater(t, comps.UnderPoint.Z, -float64EqualityThreshold/2)
 	assert.Less(t, comps.Point.Z, comps.UnderPoint.Z)
 }
+
+func TestThicknessIsDistanceToExit(t *testing.T) {
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 2))
+	shape := NewGlassSphere()
+	xs := NewIntersections(NewIntersection(2, shape), NewIntersection(3, shape))
+
+	entering := PrepareComputations(xs[0], r, xs)
+	assert.InDelta(t, 2.0, entering.Thickness, float64EqualityThreshold)
+
+	exiting := PrepareComputations(xs[1], r, xs)
+	assert.InDelta(t, 0.0, exiting.Thickness, float64EqualityThreshold)
+}
@@ -1,10 +1,6 @@
 package internal
 
-import (
-	"math"
-	"math/rand"
-	"time"
-)
+import "math"
 
 type Cone struct {
 	ID               int64
@@ -12,20 +8,18 @@ type Cone struct {
 	Transform        Matrix
 	Inverse          Matrix
 	InverseTranspose Matrix
+	Motion           *Motion
 	Parent           Shape
+	media            mediumLink
 	Minimum          float64
 	Maximum          float64
 	Closed           bool
 	HasShadow        bool
 }
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 func NewCone() *Cone {
 	return &Cone{
-		ID:        rand.Int63(),
+		ID:        nextShapeID(),
 		Material:  NewDefaultMaterial(),
 		Transform: NewIdentity4(),
 		Parent:    nil,
@@ -40,6 +34,10 @@ func (cone *Cone) GetID() int64 {
 	return cone.ID
 }
 
+func (cone *Cone) SetID(id int64) {
+	cone.ID = id
+}
+
 func (cone *Cone) LocalIntersect(ray Ray) Intersections {
 	var xs []Intersection
 
@@ -116,12 +114,21 @@ func (cone *Cone) GetInverseTranspose() Matrix {
 	return cone.InverseTranspose
 }
 
+func (cone *Cone) GetMotion() *Motion {
+	return cone.Motion
+}
+
+func (cone *Cone) SetMotion(motion *Motion) {
+	cone.Motion = motion
+}
+
 func (cone *Cone) GetMaterial() Material {
 	return cone.Material
 }
 
 func (cone *Cone) SetMaterial(material Material) {
 	cone.Material = material
+	forgetMediumOwners(cone)
 }
 
 func (cone *Cone) IntersectCaps(ray Ray, xs Intersections) Intersections {
@@ -150,6 +157,11 @@ func (cone *Cone) GetParent() Shape {
 
 func (cone *Cone) SetParent(s Shape) {
 	cone.Parent = s
+	forgetMediumOwners(cone)
+}
+
+func (cone *Cone) mediumLink() *mediumLink {
+	return &cone.media
 }
 
 func (cone *Cone) CastsShadow() bool {
@@ -1,10 +1,6 @@
 package internal
 
-import (
-	"math/rand"
-	"sort"
-	"time"
-)
+import "sort"
 
 type CSGOperation int
 
@@ -24,20 +20,18 @@ type CSG struct {
 	Transform        Matrix
 	Inverse          Matrix
 	InverseTranspose Matrix
+	Motion           *Motion
 	Parent           Shape
+	media            mediumLink
 	Operation        CSGOperation
 	Left             Shape
 	Right            Shape
 	HasShadow        bool
 }
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 func NewCSG(op CSGOperation, left, right Shape) *CSG {
 	csg := &CSG{
-		ID:               rand.Int63(),
+		ID:               nextShapeID(),
 		Material:         NewDefaultMaterial(),
 		Transform:        NewIdentity4(),
 		Inverse:          NewIdentity4(),
@@ -59,6 +53,10 @@ func (csg *CSG) GetID() int64 {
 	return csg.ID
 }
 
+func (csg *CSG) SetID(id int64) {
+	csg.ID = id
+}
+
 func (csg *CSG) LocalIntersect(ray Ray) Intersections {
 	if RayIntersectsBox(BoundsOf(csg), ray) {
 		leftXS := Intersect(csg.Left, ray)
@@ -97,12 +95,21 @@ func (csg *CSG) GetInverseTranspose() Matrix {
 	return csg.InverseTranspose
 }
 
+func (csg *CSG) GetMotion() *Motion {
+	return csg.Motion
+}
+
+func (csg *CSG) SetMotion(motion *Motion) {
+	csg.Motion = motion
+}
+
 func (csg *CSG) GetMaterial() Material {
 	return csg.Material
 }
 
 func (csg *CSG) SetMaterial(material Material) {
 	csg.Material = material
+	forgetMediumOwners(csg)
 }
 
 func (csg *CSG) GetParent() Shape {
@@ -111,6 +118,11 @@ func (csg *CSG) GetParent() Shape {
 
 func (csg *CSG) SetParent(s Shape) {
 	csg.Parent = s
+	forgetMediumOwners(csg)
+}
+
+func (csg *CSG) mediumLink() *mediumLink {
+	return &csg.media
 }
 
 func (csg *CSG) CastsShadow() bool {
@@ -1,10 +1,6 @@
 package internal
 
-import (
-	"math"
-	"math/rand"
-	"time"
-)
+import "math"
 
 type Cube struct {
 	ID               int64
@@ -12,17 +8,15 @@ type Cube struct {
 	Transform        Matrix
 	Inverse          Matrix
 	InverseTranspose Matrix
+	Motion           *Motion
 	Parent           Shape
+	media            mediumLink
 	HasShadow        bool
 }
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 func NewCube() *Cube {
 	return &Cube{
-		ID:               rand.Int63(),
+		ID:               nextShapeID(),
 		Material:         NewDefaultMaterial(),
 		Transform:        NewIdentity4(),
 		Inverse:          NewIdentity4(),
@@ -36,6 +30,10 @@ func (c *Cube) GetID() int64 {
 	return c.ID
 }
 
+func (c *Cube) SetID(id int64) {
+	c.ID = id
+}
+
 func (c *Cube) LocalIntersect(localRay Ray) Intersections {
 	xtMin, xtMax := CheckAxis(localRay.Origin.X, localRay.Direction.X, -1, 1)
 	ytMin, ytMax := CheckAxis(localRay.Origin.Y, localRay.Direction.Y, -1, 1)
@@ -85,12 +83,21 @@ func (c *Cube) GetInverseTranspose() Matrix {
 	return c.InverseTranspose
 }
 
+func (c *Cube) GetMotion() *Motion {
+	return c.Motion
+}
+
+func (c *Cube) SetMotion(motion *Motion) {
+	c.Motion = motion
+}
+
 func (c *Cube) GetMaterial() Material {
 	return c.Material
 }
 
 func (c *Cube) SetMaterial(material Material) {
 	c.Material = material
+	forgetMediumOwners(c)
 }
 
 func (c *Cube) GetParent() Shape {
@@ -99,6 +106,11 @@ func (c *Cube) GetParent() Shape {
 
 func (c *Cube) SetParent(s Shape) {
 	c.Parent = s
+	forgetMediumOwners(c)
+}
+
+func (c *Cube) mediumLink() *mediumLink {
+	return &c.media
 }
 
 func (c *Cube) CastsShadow() bool {
@@ -1,10 +1,6 @@
 package internal
 
-import (
-	"math"
-	"math/rand"
-	"time"
-)
+import "math"
 
 type Cylinder struct {
 	ID               int64
@@ -12,20 +8,18 @@ type Cylinder struct {
 	Transform        Matrix
 	Inverse          Matrix
 	InverseTranspose Matrix
+	Motion           *Motion
 	Parent           Shape
+	media            mediumLink
 	Minimum          float64
 	Maximum          float64
 	Closed           bool
 	HasShadow        bool
 }
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 func NewCylinder() *Cylinder {
 	return &Cylinder{
-		ID:               rand.Int63(),
+		ID:               nextShapeID(),
 		Material:         NewDefaultMaterial(),
 		Transform:        NewIdentity4(),
 		Inverse:          NewIdentity4(),
@@ -42,6 +36,10 @@ func (cyl *Cylinder) GetID() int64 {
 	return cyl.ID
 }
 
+func (cyl *Cylinder) SetID(id int64) {
+	cyl.ID = id
+}
+
 func (cyl *Cylinder) LocalIntersect(ray Ray) Intersections {
 	var xs []Intersection
 
@@ -103,12 +101,21 @@ func (cyl *Cylinder) GetInverseTranspose() Matrix {
 	return cyl.InverseTranspose
 }
 
+func (cyl *Cylinder) GetMotion() *Motion {
+	return cyl.Motion
+}
+
+func (cyl *Cylinder) SetMotion(motion *Motion) {
+	cyl.Motion = motion
+}
+
 func (cyl *Cylinder) GetMaterial() Material {
 	return cyl.Material
 }
 
 func (cyl *Cylinder) SetMaterial(material Material) {
 	cyl.Material = material
+	forgetMediumOwners(cyl)
 }
 
 func (cyl *Cylinder) IntersectCaps(ray Ray, xs Intersections) Intersections {
@@ -137,6 +144,11 @@ func (cyl *Cylinder) GetParent() Shape {
 
 func (cyl *Cylinder) SetParent(s Shape) {
 	cyl.Parent = s
+	forgetMediumOwners(cyl)
+}
+
+func (cyl *Cylinder) mediumLink() *mediumLink {
+	return &cyl.media
 }
 
 func (cyl *Cylinder) CastsShadow() bool {
@@ -0,0 +1,184 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+type EnvironmentLight struct {
+	Map       *Canvas
+	Intensity float64
+	Rotation  float64
+	Samples   int
+
+	rowCDF    []float64
+	columnCDF [][]float64
+	weights   []float64
+	total     float64
+}
+
+func NewEnvironmentLight(image *Canvas) *EnvironmentLight {
+	env := &EnvironmentLight{
+		Map:       image,
+		Intensity: 1,
+		Samples:   16,
+	}
+
+	env.buildDistribution()
+
+	return env
+}
+
+func (e *EnvironmentLight) buildDistribution() {
+	w, h := e.Map.W, e.Map.H
+	e.weights = make([]float64, w*h)
+	e.columnCDF = make([][]float64, h)
+	e.rowCDF = make([]float64, h)
+	e.total = 0
+
+	for y := 0; y < h; y++ {
+		sinTheta := math.Sin(math.Pi * (float64(y) + 0.5) / float64(h))
+		cdf := make([]float64, w)
+		row := 0.0
+
+		for x := 0; x < w; x++ {
+			weight := luminance(e.Map.GetColorAtPixel(x, y)) * sinTheta
+			e.weights[x+w*y] = weight
+			row += weight
+			cdf[x] = row
+		}
+
+		e.columnCDF[y] = cdf
+		e.total += row
+		e.rowCDF[y] = e.total
+	}
+}
+
+func (e *EnvironmentLight) Radiance(direction Tuple) Color {
+	u, v := e.directionToUV(direction)
+	x := minInt(int(u*float64(e.Map.W)), e.Map.W-1)
+	y := minInt(int(v*float64(e.Map.H)), e.Map.H-1)
+
+	return ColorScalarMultiply(e.Map.GetColorAtPixel(x, y), e.Intensity)
+}
+
+func (e *EnvironmentLight) ColorFor(direction Tuple) Color {
+	return e.Radiance(direction)
+}
+
+func (e *EnvironmentLight) Sample(rng *rand.Rand) (Tuple, float64, bool) {
+	if e.total <= 0 {
+		return Tuple{}, 0, false
+	}
+
+	w, h := e.Map.W, e.Map.H
+	y := searchCDF(e.rowCDF, rng.Float64()*e.total)
+	rowStart := 0.0
+
+	if y > 0 {
+		rowStart = e.rowCDF[y-1]
+	}
+
+	cdf := e.columnCDF[y]
+	x := searchCDF(cdf, rng.Float64()*(e.rowCDF[y]-rowStart))
+
+	u := (float64(x) + rng.Float64()) / float64(w)
+	v := (float64(y) + rng.Float64()) / float64(h)
+	sinTheta := math.Sin(math.Pi * v)
+
+	if sinTheta <= 0 {
+		return Tuple{}, 0, false
+	}
+
+	pdf := e.weights[x+w*y] / e.total * float64(w*h) / (2 * math.Pi * math.Pi * sinTheta)
+
+	return e.uvToDirection(u, v), pdf, true
+}
+
+func (e *EnvironmentLight) directionToUV(direction Tuple) (float64, float64) {
+	d := Normalize(MatrixTupleMultiply(RotateY(-e.Rotation), direction))
+	u := math.Atan2(d.Z, d.X) / (2 * math.Pi)
+
+	if u < 0 {
+		u++
+	}
+
+	v := math.Acos(math.Max(-1, math.Min(1, d.Y))) / math.Pi
+
+	return u, v
+}
+
+func (e *EnvironmentLight) uvToDirection(u, v float64) Tuple {
+	phi := 2 * math.Pi * u
+	theta := math.Pi * v
+	d := NewVector(math.Sin(theta)*math.Cos(phi), math.Cos(theta), math.Sin(theta)*math.Sin(phi))
+
+	return MatrixTupleMultiply(RotateY(e.Rotation), d)
+}
+
+func EnvironmentLighting(w World, comps Computation) Color {
+	env := w.Environment
+
+	if env == nil || env.Samples < 1 {
+		return black
+	}
+
+	m := fresnelShadingMaterial(comps)
+	color := HadamardProduct(surfaceColor(m, comps.Object, comps.OverPoint, comps.Time), ColorScalarMultiply(white, m.Diffuse))
+	rng := w.Rand()
+
+	var total Color
+
+	for i := 0; i < env.Samples; i++ {
+		direction, pdf, ok := env.Sample(rng)
+
+		if !ok || pdf <= 0 {
+			continue
+		}
+
+		cosine := Dot(direction, comps.NormalV)
+
+		if cosine <= 0 || environmentOccluded(w, comps.OverPoint, direction) {
+			continue
+		}
+
+		radiance := ColorScalarMultiply(env.Radiance(direction), 1/(pdf*math.Pi))
+		contribution := ColorScalarMultiply(HadamardProduct(color, radiance), cosine)
+
+		reflectDotEye := Dot(Reflect(Negate(direction), comps.NormalV), comps.EyeV)
+
+		if reflectDotEye > 0 {
+			specular := ColorScalarMultiply(radiance, m.Specular*math.Pow(reflectDotEye, m.Shininess))
+			contribution = AddColors(contribution, specular)
+		}
+
+		total = AddColors(total, contribution)
+	}
+
+	return ColorScalarDivide(total, float64(env.Samples))
+}
+
+func environmentOccluded(w World, point, direction Tuple) bool {
+	for _, hit := range IntersectWorld(w, w.ShadowRay(point, direction)) {
+		if hit.T > 0 && hit.Object.CastsShadow() && !isMediumBoundary(hit.Object) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func searchCDF(cdf []float64, value float64) int {
+	i := sort.SearchFloat64s(cdf, value)
+
+	if i >= len(cdf) {
+		return len(cdf) - 1
+	}
+
+	return i
+}
+
+func luminance(c Color) float64 {
+	return 0.2126*c.R + 0.7152*c.G + 0.0722*c.B
+}
@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func uniformEnvironment(color Color) *EnvironmentLight {
+	image := NewCanvas(16, 8)
+
+	for i := range image.Pixels {
+		image.Pixels[i] = color
+	}
+
+	return NewEnvironmentLight(image)
+}
+
+func TestEnvironmentDirectionMappingRoundTrips(t *testing.T) {
+	env := uniformEnvironment(white)
+	env.Rotation = 0.7
+
+	for _, d := range []Tuple{NewVector(1, 0, 0), Normalize(NewVector(-1, 2, 3)), Normalize(NewVector(0.2, -1, -0.4))} {
+		u, v := env.directionToUV(d)
+		assert.True(t, TupleEquals(d, env.uvToDirection(u, v)))
+	}
+}
+
+func TestEnvironmentRotationTurnsTheMap(t *testing.T) {
+	image := NewCanvas(4, 2)
+	image.WritePixelAtCoord(0, 1, NewColor(1, 0, 0))
+	env := NewEnvironmentLight(image)
+	env.Intensity = 2
+
+	d := Normalize(NewVector(1, -0.5, 0.5))
+
+	assert.True(t, ColorEquals(NewColor(2, 0, 0), env.Radiance(d)))
+
+	env.Rotation = math.Pi
+	assert.True(t, ColorEquals(black, env.Radiance(d)))
+	assert.True(t, ColorEquals(NewColor(2, 0, 0), env.Radiance(MatrixTupleMultiply(RotateY(math.Pi), d))))
+}
+
+func TestEnvironmentSamplePdfCoversTheSphere(t *testing.T) {
+	env := uniformEnvironment(white)
+	rng := rand.New(rand.NewSource(1))
+	inverse := 0.0
+	n := 20000
+
+	for i := 0; i < n; i++ {
+		d, pdf, ok := env.Sample(rng)
+
+		assert.True(t, ok)
+		assert.InDelta(t, 1.0, Magnitude(d), float64EqualityThreshold)
+		inverse += 1 / pdf
+	}
+
+	assert.InDelta(t, 4*math.Pi, inverse/float64(n), 0.1)
+}
+
+func TestEnvironmentSamplingFavoursBrightTexels(t *testing.T) {
+	image := NewCanvas(16, 8)
+
+	for i := range image.Pixels {
+		image.Pixels[i] = NewColor(0.01, 0.01, 0.01)
+	}
+
+	image.WritePixelAtCoord(4, 2, NewColor(500, 500, 500))
+	env := NewEnvironmentLight(image)
+	rng := rand.New(rand.NewSource(2))
+	bright := 0
+
+	for i := 0; i < 200; i++ {
+		d, _, _ := env.Sample(rng)
+
+		if env.Radiance(d).R > 1 {
+			bright++
+		}
+	}
+
+	assert.True(t, bright > 180)
+}
+
+func TestEnvironmentLightsDiffuseSurface(t *testing.T) {
+	w := NewWorld()
+	w.Environment = uniformEnvironment(white)
+	w.Environment.Samples = 4000
+
+	floor := NewPlane()
+	floor.Material.Specular = 0
+	floor.Material.Ambient = 0
+	w.Objects = append(w.Objects, floor)
+
+	r := NewRay(NewPoint(0, 1, -1), Normalize(NewVector(0, -1, 1)))
+	w = w.WithRand(rand.New(rand.NewSource(3)))
+	color := ColorAt(w, r, RecursionDepth)
+
+	assert.InDelta(t, 0.9, color.R, 0.05)
+	assert.InDelta(t, color.R, color.G, 1e-9)
+}
+
+func TestEnvironmentIsOccludedByGeometry(t *testing.T) {
+	w := NewWorld()
+	w.Environment = uniformEnvironment(white)
+	w.Environment.Samples = 200
+
+	floor := NewPlane()
+	floor.Material.Specular = 0
+	roof := NewPlane()
+	roof.SetTransform(Translate(0, 0.5, 0))
+	w.Objects = append(w.Objects, floor, roof)
+
+	comps := PrepareComputations(NewIntersection(math.Sqrt(2)*0.25, floor), NewRay(NewPoint(0, 0.25, -0.25), Normalize(NewVector(0, -1, 1))), nil)
+
+	assert.True(t, ColorEquals(black, EnvironmentLighting(w, comps)))
+}
+
+func TestEscapedRaysSeeEnvironment(t *testing.T) {
+	w := NewWorld()
+	w.Environment = uniformEnvironment(NewColor(0.2, 0.4, 0.6))
+	w.Background = NewSolidBackground(white)
+
+	r := NewRay(NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	assert.True(t, ColorEquals(NewColor(0.2, 0.4, 0.6), ColorAt(w, r, RecursionDepth)))
+}
+
+func TestEnvironmentLightingTakesTheFresnelShareFromDiffuse(t *testing.T) {
+	w := NewWorld()
+	w.Environment = uniformEnvironment(white)
+	w.Environment.Samples = 50
+
+	floor := NewPlane()
+	floor.Material.Specular = 0
+	w.Objects = append(w.Objects, floor)
+
+	r := NewRay(NewPoint(0, 1, -1), Normalize(NewVector(0, -1, 1)))
+	xs := NewIntersections(NewIntersection(math.Sqrt(2), floor))
+	lit := func() Color {
+		comps := PrepareComputations(xs[0], r, xs)
+		return EnvironmentLighting(w.WithRand(rand.New(rand.NewSource(5))), comps)
+	}
+
+	plain := lit()
+	floor.Material.Reflective = 1
+	floor.Material.RefractiveIndex = 1.5
+	comps := PrepareComputations(xs[0], r, xs)
+
+	assert.Greater(t, plain.R, 0.0)
+	assert.InDelta(t, plain.R*(1-Schlick(comps)), lit().R, 1e-9)
+}
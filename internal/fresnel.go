@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+)
+
+type FresnelMode int
+
+const (
+	FresnelAuto FresnelMode = iota
+	FresnelNone
+	FresnelSchlick
+	FresnelDielectric
+	FresnelConductor
+)
+
+var fresnelModes = []FresnelMode{FresnelAuto, FresnelNone, FresnelSchlick, FresnelDielectric, FresnelConductor}
+
+func (f FresnelMode) String() string {
+	return [...]string{"auto", "none", "schlick", "dielectric", "conductor"}[f]
+}
+
+func ParseFresnelMode(name string) (FresnelMode, error) {
+	for _, f := range fresnelModes {
+		if f.String() == name {
+			return f, nil
+		}
+	}
+
+	return FresnelAuto, fmt.Errorf("unknown fresnel mode %q", name)
+}
+
+func FresnelTerms(comps Computation) (Color, Color) {
+	m := comps.Object.GetMaterial()
+	mode := m.Fresnel
+
+	if mode == FresnelAuto {
+		mode = FresnelNone
+
+		if m.Reflective > 0 && (m.Transparency > 0 || m.RefractiveIndex != 1) {
+			mode = FresnelSchlick
+		}
+	}
+
+	switch mode {
+	case FresnelSchlick:
+		r := Schlick(comps)
+		return NewColor(r, r, r), NewColor(1-r, 1-r, 1-r)
+
+	case FresnelDielectric:
+		r := DielectricReflectance(comps)
+		return NewColor(r, r, r), NewColor(1-r, 1-r, 1-r)
+
+	case FresnelConductor:
+		cos := Dot(comps.EyeV, comps.NormalV)
+		n1 := math.Max(comps.N1, 1e-6)
+
+		return NewColor(
+			ConductorReflectance(cos, m.ConductorEta.R/n1, m.ConductorK.R/n1),
+			ConductorReflectance(cos, m.ConductorEta.G/n1, m.ConductorK.G/n1),
+			ConductorReflectance(cos, m.ConductorEta.B/n1, m.ConductorK.B/n1),
+		), black
+
+	default:
+		return white, white
+	}
+}
+
+func fresnelShadingMaterial(comps Computation) Material {
+	m := comps.Object.GetMaterial()
+	_, transmittance := FresnelTerms(comps)
+	share := meanComponent(transmittance)
+	m.Diffuse *= share
+	m.Specular *= share
+
+	return m
+}
+
+func DielectricReflectance(comps Computation) float64 {
+	cosI := math.Min(math.Abs(Dot(comps.EyeV, comps.NormalV)), 1)
+	ratio := comps.N1 / comps.N2
+	sin2T := ratio * ratio * (1 - cosI*cosI)
+
+	if sin2T >= 1 {
+		return 1
+	}
+
+	cosT := math.Sqrt(1 - sin2T)
+	rs := (comps.N1*cosI - comps.N2*cosT) / (comps.N1*cosI + comps.N2*cosT)
+	rp := (comps.N2*cosI - comps.N1*cosT) / (comps.N2*cosI + comps.N1*cosT)
+
+	return (rs*rs + rp*rp) / 2
+}
+
+func ConductorReflectance(cosI, eta, k float64) float64 {
+	cosI = math.Min(math.Max(cosI, 0), 1)
+	cos2 := cosI * cosI
+	sin2 := 1 - cos2
+	eta2, k2 := eta*eta, k*k
+
+	t0 := eta2 - k2 - sin2
+	a2b2 := math.Sqrt(t0*t0 + 4*eta2*k2)
+	t1 := a2b2 + cos2
+	a := math.Sqrt(math.Max(0.5*(a2b2+t0), 0))
+	t2 := 2 * cosI * a
+	rs := (t1 - t2) / (t1 + t2)
+
+	t3 := cos2*a2b2 + sin2*sin2
+	t4 := t2 * sin2
+	rp := rs * (t3 - t4) / (t3 + t4)
+
+	return (rs + rp) / 2
+}
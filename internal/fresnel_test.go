@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFresnelMode(t *testing.T) {
+	for _, f := range fresnelModes {
+		parsed, err := ParseFresnelMode(f.String())
+
+		assert.Nil(t, err)
+		assert.Equal(t, f, parsed)
+	}
+
+	_, err := ParseFresnelMode("cook-torrance")
+	assert.NotNil(t, err)
+}
+
+func fresnelComps(material Material, direction Tuple) Computation {
+	shape := NewPlane()
+	shape.SetMaterial(material)
+
+	r := NewRay(SubTuples(NewPoint(0, 0, 0), direction), direction)
+	xs := NewIntersections(NewIntersection(1, shape))
+
+	return PrepareComputations(xs[0], r, xs)
+}
+
+func TestDielectricReflectance(t *testing.T) {
+	m := NewDefaultMaterial()
+	m.RefractiveIndex = 1.5
+
+	normal := fresnelComps(m, NewVector(0, -1, 0))
+	assert.InDelta(t, 0.04, DielectricReflectance(normal), 1e-9)
+
+	grazing := fresnelComps(m, Normalize(NewVector(1, -0.01, 0)))
+	assert.Greater(t, DielectricReflectance(grazing), 0.9)
+
+	inside := normal
+	inside.N1, inside.N2 = 1.5, 1
+	inside.EyeV = Normalize(NewVector(1, 0.5, 0))
+	assert.Equal(t, 1.0, DielectricReflectance(inside))
+}
+
+func TestConductorReflectance(t *testing.T) {
+	eta, k := 0.2, 3.9
+	expected := ((eta-1)*(eta-1) + k*k) / ((eta+1)*(eta+1) + k*k)
+
+	assert.InDelta(t, expected, ConductorReflectance(1, eta, k), 1e-9)
+	assert.InDelta(t, 1.0, ConductorReflectance(0, eta, k), 1e-9)
+	assert.InDelta(t, 0.04, ConductorReflectance(1, 1.5, 0), 1e-9)
+}
+
+func TestFresnelTermsByMode(t *testing.T) {
+	m := NewDefaultMaterial()
+	m.Reflective = 1
+	m.RefractiveIndex = 1.5
+	direction := Normalize(NewVector(1, -1, 0))
+
+	comps := fresnelComps(m, direction)
+	reflected, transmitted := FresnelTerms(comps)
+	assert.InDelta(t, Schlick(comps), reflected.R, 1e-9)
+
+	m.RefractiveIndex = 1
+	reflected, transmitted = FresnelTerms(fresnelComps(m, direction))
+	assert.True(t, ColorEquals(white, reflected))
+	assert.True(t, ColorEquals(white, transmitted))
+
+	m.Transparency = 0.5
+	comps = fresnelComps(m, direction)
+	reflected, _ = FresnelTerms(comps)
+	assert.InDelta(t, Schlick(comps), reflected.R, 1e-9)
+	m.Transparency = 0
+
+	m.RefractiveIndex = 1.5
+	m.Fresnel = FresnelSchlick
+	comps = fresnelComps(m, direction)
+	reflected, transmitted = FresnelTerms(comps)
+	assert.InDelta(t, Schlick(comps), reflected.R, 1e-9)
+	assert.InDelta(t, 1, reflected.G+transmitted.G, 1e-9)
+
+	m.Fresnel = FresnelDielectric
+	comps = fresnelComps(m, direction)
+	reflected, _ = FresnelTerms(comps)
+	assert.InDelta(t, DielectricReflectance(comps), reflected.B, 1e-9)
+
+	m.Fresnel = FresnelConductor
+	m.ConductorEta = NewColor(0.18, 0.42, 1.37)
+	m.ConductorK = NewColor(3.42, 2.35, 1.77)
+	reflected, transmitted = FresnelTerms(fresnelComps(m, NewVector(0, -1, 0)))
+	assert.Greater(t, reflected.R, reflected.B)
+	assert.True(t, ColorEquals(black, transmitted))
+}
+
+func TestReflectedColorFollowsFresnel(t *testing.T) {
+	w := NewWorld()
+	w.Background = NewSolidBackground(NewColor(1, 1, 1))
+	floor := NewPlane()
+	floor.SetTransform(Translate(0, -1, 0))
+	floor.Material.Reflective = 1
+	floor.Material.RefractiveIndex = 1.5
+	floor.Material.Fresnel = FresnelDielectric
+	w.Objects = append(w.Objects, floor)
+
+	reflected := func(direction Tuple) Color {
+		r := NewRay(NewPoint(0, 0, -3), Normalize(direction))
+		xs := IntersectWorld(w, r)
+		comps := PrepareComputations(Hit(xs), r, xs)
+
+		return ReflectedColor(w, comps, RecursionDepth)
+	}
+
+	steep := reflected(NewVector(0, -1, 0.2))
+	grazing := reflected(NewVector(0, -math.Sin(0.05), math.Cos(0.05)))
+
+	assert.InDelta(t, 0.04, steep.R, 0.01)
+	assert.Greater(t, grazing.R, 0.5)
+}
+
+func TestFresnelTakesTheReflectedShareFromDiffuseAndSpecular(t *testing.T) {
+	m := NewDefaultMaterial()
+	m.Reflective = 0.5
+	m.RefractiveIndex = 1.5
+	direction := Normalize(NewVector(1, -0.2, 0))
+
+	comps := fresnelComps(m, direction)
+	auto := fresnelShadingMaterial(comps)
+	assert.InDelta(t, m.Diffuse*(1-Schlick(comps)), auto.Diffuse, 1e-9)
+	assert.InDelta(t, m.Specular*(1-Schlick(comps)), auto.Specular, 1e-9)
+
+	m.Fresnel = FresnelNone
+	none := fresnelShadingMaterial(fresnelComps(m, direction))
+	assert.Equal(t, m.Diffuse, none.Diffuse)
+	assert.Equal(t, m.Specular, none.Specular)
+
+	m.Fresnel = FresnelDielectric
+	comps = fresnelComps(m, direction)
+	r := DielectricReflectance(comps)
+	shaded := fresnelShadingMaterial(comps)
+	assert.InDelta(t, m.Diffuse*(1-r), shaded.Diffuse, 1e-9)
+	assert.InDelta(t, m.Specular*(1-r), shaded.Specular, 1e-9)
+	assert.Equal(t, m.Ambient, shaded.Ambient)
+
+	m.Fresnel = FresnelConductor
+	m.ConductorEta = NewColor(0.18, 0.42, 1.37)
+	m.ConductorK = NewColor(3.42, 2.35, 1.77)
+	assert.Equal(t, 0.0, fresnelShadingMaterial(fresnelComps(m, direction)).Diffuse)
+}
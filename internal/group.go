@@ -1,10 +1,6 @@
 package internal
 
-import (
-	"math/rand"
-	"sort"
-	"time"
-)
+import "sort"
 
 type Group struct {
 	ID               int64
@@ -12,18 +8,16 @@ type Group struct {
 	Transform        Matrix
 	Inverse          Matrix
 	InverseTranspose Matrix
+	Motion           *Motion
 	Parent           Shape
+	media            mediumLink
 	Children         []Shape
 	HasShadow        bool
 }
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 func NewGroup() *Group {
 	return &Group{
-		ID:               rand.Int63(),
+		ID:               nextShapeID(),
 		Material:         NewDefaultMaterial(),
 		Transform:        NewIdentity4(),
 		Inverse:          NewIdentity4(),
@@ -38,6 +32,10 @@ func (group *Group) GetID() int64 {
 	return group.ID
 }
 
+func (group *Group) SetID(id int64) {
+	group.ID = id
+}
+
 func (group *Group) LocalIntersect(ray Ray) Intersections {
 	if RayIntersectsBox(BoundsOf(group), ray) {
 		var intersects Intersections
@@ -79,12 +77,21 @@ func (group *Group) GetInverseTranspose() Matrix {
 	return group.InverseTranspose
 }
 
+func (group *Group) GetMotion() *Motion {
+	return group.Motion
+}
+
+func (group *Group) SetMotion(motion *Motion) {
+	group.Motion = motion
+}
+
 func (group *Group) GetMaterial() Material {
 	return group.Material
 }
 
 func (group *Group) SetMaterial(material Material) {
 	group.Material = material
+	forgetMediumOwners(group)
 }
 
 func (group *Group) GetParent() Shape {
@@ -93,6 +100,11 @@ func (group *Group) GetParent() Shape {
 
 func (group *Group) SetParent(s Shape) {
 	group.Parent = s
+	forgetMediumOwners(group)
+}
+
+func (group *Group) mediumLink() *mediumLink {
+	return &group.media
 }
 
 func (group *Group) CastsShadow() bool {
@@ -0,0 +1,194 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+func LoadHDR(path string) (*Canvas, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	image, err := ReadHDR(file)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return image, nil
+}
+
+func ReadHDR(r io.Reader) (*Canvas, error) {
+	reader := bufio.NewReader(r)
+	magic, err := reader.ReadString('\n')
+
+	if err != nil || !strings.HasPrefix(magic, "#?") {
+		return nil, fmt.Errorf("not a Radiance HDR file")
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+
+		if err != nil {
+			return nil, fmt.Errorf("truncated HDR header")
+		}
+
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			break
+		}
+
+		if strings.HasPrefix(line, "FORMAT=") && line != "FORMAT=32-bit_rle_rgbe" {
+			return nil, fmt.Errorf("unsupported HDR format %q", strings.TrimPrefix(line, "FORMAT="))
+		}
+	}
+
+	resolution, err := reader.ReadString('\n')
+
+	if err != nil {
+		return nil, fmt.Errorf("missing HDR resolution")
+	}
+
+	var width, height int
+
+	if _, err := fmt.Sscanf(resolution, "-Y %d +X %d", &height, &width); err != nil || width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("unsupported HDR resolution %q", strings.TrimSpace(resolution))
+	}
+
+	image := NewCanvas(width, height)
+	scanline := make([]byte, 4*width)
+
+	for y := 0; y < height; y++ {
+		if err := readHDRScanline(reader, scanline, width); err != nil {
+			return nil, fmt.Errorf("scanline %d: %v", y, err)
+		}
+
+		for x := 0; x < width; x++ {
+			image.WritePixelAtCoord(x, y, rgbeToColor(scanline[4*x:4*x+4]))
+		}
+	}
+
+	return image, nil
+}
+
+func readHDRScanline(r *bufio.Reader, scanline []byte, width int) error {
+	if width < 8 || width > 0x7fff {
+		_, err := io.ReadFull(r, scanline)
+		return err
+	}
+
+	header, err := r.Peek(4)
+
+	if err != nil {
+		return err
+	}
+
+	if header[0] != 2 || header[1] != 2 || header[2]&0x80 != 0 {
+		_, err := io.ReadFull(r, scanline)
+		return err
+	}
+
+	if int(header[2])<<8|int(header[3]) != width {
+		return fmt.Errorf("scanline width mismatch")
+	}
+
+	r.Discard(4)
+	channel := make([]byte, width)
+
+	for c := 0; c < 4; c++ {
+		for x := 0; x < width; {
+			count, err := r.ReadByte()
+
+			if err != nil {
+				return err
+			}
+
+			if count > 128 {
+				run := int(count) - 128
+				value, err := r.ReadByte()
+
+				if err != nil {
+					return err
+				}
+
+				if x+run > width {
+					return fmt.Errorf("run overflows scanline")
+				}
+
+				for i := 0; i < run; i++ {
+					channel[x+i] = value
+				}
+
+				x += run
+				continue
+			}
+
+			if count == 0 || x+int(count) > width {
+				return fmt.Errorf("bad run length")
+			}
+
+			if _, err := io.ReadFull(r, channel[x:x+int(count)]); err != nil {
+				return err
+			}
+
+			x += int(count)
+		}
+
+		for x := 0; x < width; x++ {
+			scanline[4*x+c] = channel[x]
+		}
+	}
+
+	return nil
+}
+
+func rgbeToColor(rgbe []byte) Color {
+	if rgbe[3] == 0 {
+		return black
+	}
+
+	f := math.Ldexp(1, int(rgbe[3])-(128+8))
+
+	return NewColor(float64(rgbe[0])*f, float64(rgbe[1])*f, float64(rgbe[2])*f)
+}
+
+func colorToRGBE(c Color) []byte {
+	v := maxComponent(c)
+
+	if v < 1e-32 {
+		return []byte{0, 0, 0, 0}
+	}
+
+	m, e := math.Frexp(v)
+	scale := m * 256 / v
+
+	return []byte{
+		byte(math.Max(0, c.R) * scale),
+		byte(math.Max(0, c.G) * scale),
+		byte(math.Max(0, c.B) * scale),
+		byte(e + 128),
+	}
+}
+
+func (c *Canvas) ToHDR() []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y %d +X %d\n", c.H, c.W)
+
+	for _, p := range c.Pixels {
+		b.Write(colorToRGBE(p))
+	}
+
+	return b.Bytes()
+}
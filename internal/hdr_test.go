@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHDRRoundTrip(t *testing.T) {
+	c := NewCanvas(3, 2)
+	c.WritePixelAtCoord(0, 0, NewColor(1, 0.5, 0.25))
+	c.WritePixelAtCoord(2, 1, NewColor(40, 20, 10))
+
+	image, err := ReadHDR(bytes.NewReader(c.ToHDR()))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, image.W)
+	assert.Equal(t, 2, image.H)
+	assert.True(t, ColorEquals(NewColor(1, 0.5, 0.25), image.GetColorAtPixel(0, 0)))
+	assert.True(t, ColorEquals(NewColor(40, 20, 10), image.GetColorAtPixel(2, 1)))
+	assert.True(t, ColorEquals(black, image.GetColorAtPixel(1, 0)))
+}
+
+func TestReadRunLengthEncodedHDR(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString("#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y 1 +X 8\n")
+	b.Write([]byte{2, 2, 0, 8})
+	b.Write([]byte{128 + 8, 128})
+	b.Write([]byte{4, 64, 64, 64, 64, 128 + 4, 0})
+	b.Write([]byte{128 + 8, 0})
+	b.Write([]byte{128 + 8, 129})
+
+	image, err := ReadHDR(&b)
+
+	assert.Nil(t, err)
+	assert.True(t, ColorEquals(NewColor(1, 0.5, 0), image.GetColorAtPixel(0, 0)))
+	assert.True(t, ColorEquals(NewColor(1, 0, 0), image.GetColorAtPixel(7, 0)))
+}
+
+func TestReadHDRRejectsOtherFiles(t *testing.T) {
+	_, err := ReadHDR(bytes.NewReader([]byte("P3\n1 1\n255\n0 0 0\n")))
+	assert.NotNil(t, err)
+
+	_, err = ReadHDR(bytes.NewReader([]byte("#?RADIANCE\nFORMAT=32-bit_rle_xyze\n\n-Y 1 +X 1\n")))
+	assert.NotNil(t, err)
+
+	_, err = ReadHDR(bytes.NewReader([]byte("#?RADIANCE\n\n-Y 2 +X 2\n\x80\x80\x80\x81")))
+	assert.NotNil(t, err)
+}
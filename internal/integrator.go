@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+type Integrator interface {
+	Li(w World, r Ray, rng *rand.Rand) Color
+}
+
+type IntegratorFactory func(opts RenderOptions) Integrator
+
+var (
+	integratorsMu sync.RWMutex
+	integrators   = map[string]IntegratorFactory{
+		"whitted": func(opts RenderOptions) Integrator {
+			return WhittedIntegrator{Depth: opts.Depth}
+		},
+		"path": func(opts RenderOptions) Integrator {
+			return PathIntegrator{MaxBounces: opts.MaxBounces, RouletteStart: opts.RouletteStart}
+		},
+		"flat": func(opts RenderOptions) Integrator {
+			return FlatIntegrator{}
+		},
+		"normal": func(opts RenderOptions) Integrator {
+			return NormalIntegrator{}
+		},
+		"depth": func(opts RenderOptions) Integrator {
+			return DepthIntegrator{Far: 20}
+		},
+		"ao": func(opts RenderOptions) Integrator {
+			return AmbientOcclusionIntegrator{Samples: 16, Distance: 1}
+		},
+	}
+)
+
+func RegisterIntegrator(name string, factory IntegratorFactory) error {
+	integratorsMu.Lock()
+	defer integratorsMu.Unlock()
+
+	if factory == nil {
+		return fmt.Errorf("integrator %q has no factory", name)
+	}
+
+	if _, ok := integrators[name]; ok {
+		return fmt.Errorf("integrator %q is already registered", name)
+	}
+
+	integrators[name] = factory
+
+	return nil
+}
+
+func NewIntegrator(name string, opts RenderOptions) (Integrator, error) {
+	integratorsMu.RLock()
+	factory, ok := integrators[name]
+	integratorsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown integrator %q", name)
+	}
+
+	return factory(opts), nil
+}
+
+func IntegratorNames() []string {
+	integratorsMu.RLock()
+	defer integratorsMu.RUnlock()
+
+	names := make([]string, 0, len(integrators))
+
+	for name := range integrators {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+type WhittedIntegrator struct {
+	Depth int
+}
+
+func (i WhittedIntegrator) Li(w World, r Ray, rng *rand.Rand) Color {
+	return ColorAt(w, r, i.Depth)
+}
+
+type FlatIntegrator struct{}
+
+func (i FlatIntegrator) Li(w World, r Ray, rng *rand.Rand) Color {
+	comps, ok := firstHit(w, r)
+
+	if !ok {
+		return w.BackgroundColor(r)
+	}
+
+	return surfaceColor(comps.Object.GetMaterial(), comps.Object, comps.Point, comps.Time)
+}
+
+type NormalIntegrator struct{}
+
+func (i NormalIntegrator) Li(w World, r Ray, rng *rand.Rand) Color {
+	comps, ok := firstHit(w, r)
+
+	if !ok {
+		return black
+	}
+
+	n := comps.NormalV
+
+	return NewColor((n.X+1)/2, (n.Y+1)/2, (n.Z+1)/2)
+}
+
+type DepthIntegrator struct {
+	Near float64
+	Far  float64
+}
+
+func (i DepthIntegrator) Li(w World, r Ray, rng *rand.Rand) Color {
+	hit := Hit(IntersectWorld(w, r))
+
+	if hit == (Intersection{}) || i.Far <= i.Near {
+		return black
+	}
+
+	d := hit.T * Magnitude(r.Direction)
+	shade := 1 - math.Min(math.Max((d-i.Near)/(i.Far-i.Near), 0), 1)
+
+	return NewColor(shade, shade, shade)
+}
+
+type AmbientOcclusionIntegrator struct {
+	Samples  int
+	Distance float64
+}
+
+func (i AmbientOcclusionIntegrator) Li(w World, r Ray, rng *rand.Rand) Color {
+	comps, ok := firstHit(w, r)
+
+	if !ok || i.Samples < 1 {
+		return black
+	}
+
+	unoccluded := 0
+
+	for s := 0; s < i.Samples; s++ {
+		ray := SpawnRay(comps, comps.OverPoint, cosineSampleHemisphere(comps.NormalV, rng))
+		hit := Hit(IntersectWorld(w, ray))
+
+		if hit == (Intersection{}) || !hit.Object.CastsShadow() || hit.T >= i.Distance {
+			unoccluded++
+		}
+	}
+
+	shade := float64(unoccluded) / float64(i.Samples)
+
+	return NewColor(shade, shade, shade)
+}
+
+func firstHit(w World, r Ray) (Computation, bool) {
+	xs := IntersectWorld(w, r)
+	hit := Hit(xs)
+
+	if hit == (Intersection{}) {
+		return Computation{}, false
+	}
+
+	return PrepareComputations(hit, r, xs), true
+}
+
+func (opts RenderOptions) integrator() Integrator {
+	if opts.Integrator != nil {
+		return opts.Integrator
+	}
+
+	return WhittedIntegrator{Depth: opts.Depth}
+}
@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type constantIntegrator struct {
+	color Color
+}
+
+func (i constantIntegrator) Li(w World, r Ray, rng *rand.Rand) Color {
+	return i.color
+}
+
+func TestDefaultIntegratorIsWhitted(t *testing.T) {
+	w := NewDefaultWorld()
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+	opts := DefaultRenderOptions()
+
+	assert.True(t, ColorEquals(ColorAt(w, r, opts.Depth), opts.integrator().Li(w, r, nil)))
+}
+
+func TestBuiltinIntegratorsAreRegistered(t *testing.T) {
+	names := IntegratorNames()
+
+	for _, name := range []string{"whitted", "path", "flat", "normal", "depth", "ao"} {
+		assert.Contains(t, names, name)
+
+		integrator, err := NewIntegrator(name, DefaultRenderOptions())
+		assert.Nil(t, err)
+		assert.NotNil(t, integrator)
+	}
+
+	_, err := NewIntegrator("toon", DefaultRenderOptions())
+	assert.NotNil(t, err)
+}
+
+func TestRegisterCustomIntegrator(t *testing.T) {
+	red := NewColor(1, 0, 0)
+	err := RegisterIntegrator("test-constant", func(opts RenderOptions) Integrator {
+		return constantIntegrator{red}
+	})
+	assert.Nil(t, err)
+
+	err = RegisterIntegrator("test-constant", func(opts RenderOptions) Integrator {
+		return constantIntegrator{black}
+	})
+	assert.NotNil(t, err)
+
+	c := NewCamera(5, 5, math.Pi/2)
+	opts := DefaultRenderOptions()
+	opts.Integrator, _ = NewIntegrator("test-constant", opts)
+	image := RenderWithOptions(c, NewWorld(), opts)
+
+	assert.True(t, ColorEquals(red, image.GetColorAtPixel(2, 2)))
+}
+
+func TestFlatIntegratorReturnsSurfaceColor(t *testing.T) {
+	w := NewDefaultWorld()
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+
+	assert.True(t, ColorEquals(NewColor(0.8, 1.0, 0.6), FlatIntegrator{}.Li(w, r, nil)))
+	assert.True(t, ColorEquals(black, FlatIntegrator{}.Li(w, NewRay(NewPoint(0, 0, -5), NewVector(0, 1, 0)), nil)))
+}
+
+func TestNormalIntegratorMapsNormalToColor(t *testing.T) {
+	w := NewDefaultWorld()
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+
+	assert.True(t, ColorEquals(NewColor(0.5, 0.5, 0), NormalIntegrator{}.Li(w, r, nil)))
+}
+
+func TestDepthIntegratorFadesWithDistance(t *testing.T) {
+	w := NewDefaultWorld()
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+
+	assert.True(t, ColorEquals(NewColor(0.6, 0.6, 0.6), DepthIntegrator{Far: 10}.Li(w, r, nil)))
+	assert.True(t, ColorEquals(black, DepthIntegrator{Far: 2}.Li(w, r, nil)))
+}
+
+func TestAmbientOcclusionDarkensCorners(t *testing.T) {
+	w := NewWorld()
+	floor := NewPlane()
+	wall := NewPlane()
+	wall.SetTransform(MatrixMultiply(Translate(0, 0, 1), RotateX(math.Pi/2)))
+	w.Objects = append(w.Objects, floor, wall)
+
+	ao := AmbientOcclusionIntegrator{Samples: 64, Distance: 2}
+	rng := rand.New(rand.NewSource(1))
+
+	open := ao.Li(w, NewRay(NewPoint(0, 1, -10), Normalize(NewVector(0, -1, 0))), rng)
+	corner := ao.Li(w, NewRay(NewPoint(0, 1, 0.9), Normalize(NewVector(0, -1, 0))), rng)
+
+	assert.True(t, ColorEquals(white, open))
+	assert.True(t, corner.R < 0.9)
+}
@@ -46,7 +46,7 @@ func Hit(intersects Intersections) Intersection {
 	var result Intersection
 
 	for _, v := range intersects {
-		if v.T > 0 {
+		if v.T > 0 && !isMediumBoundary(v.Object) {
 			result = v
 			break
 		}
@@ -55,34 +55,36 @@ func Hit(intersects Intersections) Intersection {
 	return result
 }
 
+type shading struct {
+	lights    []Color
+	direct    Color
+	reflected Color
+	refracted Color
+}
+
 func ShadeHit(w World, comps Computation, remaining int) Color {
-	var surfaceColor Color
+	parts := shadeParts(w, comps, remaining)
 
-	for _, light := range w.Lights {
-		surfaceColor = AddColors(
-			surfaceColor,
-			Lighting(comps.Object.GetMaterial(), comps.Object, light, comps.OverPoint, comps.EyeV, comps.NormalV, IntensityAt(light, comps.OverPoint, w)),
-		)
-	}
+	return AddColors(AddColors(parts.direct, parts.reflected), parts.refracted)
+}
 
-	reflected := ReflectedColor(w, comps, remaining)
-	refracted := RefractedColor(w, comps, remaining)
+func shadeParts(w World, comps Computation, remaining int) shading {
+	var parts shading
+	material := fresnelShadingMaterial(comps)
 
-	material := comps.Object.GetMaterial()
+	for _, light := range w.Lights {
+		color := LightingAtTime(material, comps.Object, light, comps.OverPoint, comps.EyeV, comps.NormalV, IntensityAt(light, comps.OverPoint, w), comps.Time, w.Rand())
+		parts.lights = append(parts.lights, color)
+		parts.direct = AddColors(parts.direct, color)
+	}
 
-	if material.Reflective > 0 && material.Transparency > 0 {
-		reflectance := Schlick(comps)
+	parts.direct = AddColors(parts.direct, EnvironmentLighting(w, comps))
+	parts.direct = AddColors(parts.direct, CausticLighting(w, comps))
 
-		return AddColors(
-			surfaceColor,
-			AddColors(
-				ColorScalarMultiply(reflected, reflectance),
-				ColorScalarMultiply(refracted, (1-reflectance)),
-			),
-		)
-	}
+	parts.reflected = ReflectedColor(w, comps, remaining)
+	parts.refracted = RefractedColor(w, comps, remaining)
 
-	return AddColors(AddColors(surfaceColor, reflected), refracted)
+	return parts
 }
 
 func ColorAt(w World, r Ray, remaining int) Color {
@@ -91,12 +93,12 @@ func ColorAt(w World, r Ray, remaining int) Color {
 	empty := Intersection{}
 
 	if hit == empty {
-		return NewColor(0, 0, 0)
+		return attenuateThroughMedia(w, r, intersections, math.Inf(1), w.BackgroundColor(r))
 	}
 
 	comps := PrepareComputations(hit, r, intersections)
 
-	return ShadeHit(w, comps, remaining)
+	return attenuateThroughMedia(w, r, intersections, hit.T, ShadeHit(w, comps, remaining))
 }
 
 func ReflectedColor(w World, comps Computation, remaining int) Color {
@@ -106,29 +108,37 @@ func ReflectedColor(w World, comps Computation, remaining int) Color {
 		return black
 	}
 
-	reflectRay := NewRay(comps.OverPoint, comps.ReflectV)
+	reflectRay := SpawnRay(comps, comps.OverPoint, comps.ReflectV)
 	color := ColorAt(w, reflectRay, remaining-1)
+	reflectance, _ := FresnelTerms(comps)
 
-	return ColorScalarMultiply(color, objectMaterial.Reflective)
+	return HadamardProduct(ColorScalarMultiply(color, objectMaterial.Reflective), reflectance)
 }
 
 func RefractedColor(w World, comps Computation, remaining int) Color {
-	indexRatio := comps.N1 / comps.N2
-	cosI := Dot(comps.EyeV, comps.NormalV)
-	sin2T := (indexRatio * indexRatio) * (1 - cosI*cosI)
+	if remaining <= 0 || comps.Object.GetMaterial().Transparency == 0 {
+		return black
+	}
+
+	direction, ok := refractDirection(comps)
 
-	if remaining <= 0 || sin2T > 1 || comps.Object.GetMaterial().Transparency == 0 {
+	if !ok {
 		return black
 	}
 
-	cosT := math.Sqrt(1.0 - sin2T)
-	direction := SubTuples(
-		TupleScalarMultiply(comps.NormalV, indexRatio*cosI-cosT),
-		TupleScalarMultiply(comps.EyeV, indexRatio),
-	)
-	refractRay := NewRay(comps.UnderPoint, direction)
-	transparency := comps.Object.GetMaterial().Transparency
-	color := ColorScalarMultiply(ColorAt(w, refractRay, remaining-1), transparency)
+	refractRay := SpawnRay(comps, comps.UnderPoint, direction)
+	material := comps.Object.GetMaterial()
+	_, transmittance := FresnelTerms(comps)
+
+	if maxComponent(transmittance) <= 0 {
+		return black
+	}
+
+	color := HadamardProduct(ColorScalarMultiply(ColorAt(w, refractRay, remaining-1), material.Transparency), transmittance)
+
+	if !comps.Inside {
+		color = HadamardProduct(color, material.AbsorptionTransmittance(comps.Thickness))
+	}
 
 	return color
 }
@@ -493,7 +493,7 @@ func TestShadeHitWithReflectiveTransparentMaterial(t *testing.T) {
 	comps := PrepareComputations(xs[0], r, xs)
 	color := ShadeHit(w, comps, RecursionDepth)
 
-	assert.True(t, ColorEquals(NewColor(0.93391, 0.69643, 0.69243), color))
+	assert.True(t, ColorEquals(NewColor(0.90924, 0.67176, 0.66776), color))
 }
 
 func TestIntersectionEncapsulatesUV(t *testing.T) {
@@ -609,3 +609,39 @@ func TestIntersectingRayAndCSGTestsChildrenIfBoxIsHit(t *testing.T) {
 	assert.NotEqual(t, Ray{}, left.SavedRay)
 	assert.NotEqual(t, Ray{}, right.SavedRay)
 }
+
+func TestRefractedColorIsAbsorbedByThickness(t *testing.T) {
+	wall := NewPlane()
+	wall.SetTransform(MatrixMultiply(Translate(0, 0, 10), RotateX(math.Pi/2)))
+	wallMaterial := NewDefaultMaterial()
+	wallMaterial.Ambient = 1
+	wallMaterial.Diffuse = 0
+	wallMaterial.Specular = 0
+	wall.SetMaterial(wallMaterial)
+
+	glassColor := func(depth float64) Color {
+		glass := NewCube()
+		glass.SetTransform(Scale(1, 1, depth))
+		glass.HasShadow = false
+		material := NewDefaultMaterial()
+		material.Ambient = 0
+		material.Diffuse = 0
+		material.Specular = 0
+		material.Transparency = 1
+		material.Absorption = NewColor(0, 1, 1)
+		material.AbsorptionDensity = 0.5
+		glass.SetMaterial(material)
+
+		w := NewWorld()
+		w.Lights = []LightSource{NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1))}
+		w.Objects = []Shape{glass, wall}
+		r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+		xs := IntersectWorld(w, r)
+		comps := PrepareComputations(xs[0], r, xs)
+
+		return RefractedColor(w, comps, RecursionDepth)
+	}
+
+	assert.True(t, ColorEquals(NewColor(1, math.Exp(-1), math.Exp(-1)), glassColor(1)))
+	assert.True(t, ColorEquals(NewColor(1, math.Exp(-2), math.Exp(-2)), glassColor(2)))
+}
@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+)
+
+func ApertureForFStop(focalLength, fStop float64) float64 {
+	if fStop <= 0 {
+		return 0
+	}
+
+	return focalLength / (2 * fStop)
+}
+
+func (c Camera) HasLens() bool {
+	return c.Aperture > 0 && c.FocalDistance > 0
+}
+
+func (c Camera) sampleLens(rng *rand.Rand) (float64, float64) {
+	if c.Blades >= 3 {
+		return samplePolygon(c.Blades, c.BladeRotation, rng.Float64(), rng.Float64(), rng.Float64())
+	}
+
+	return sampleDisk(rng.Float64(), rng.Float64())
+}
+
+func sampleDisk(u, v float64) (float64, float64) {
+	a, b := 2*u-1, 2*v-1
+
+	if a == 0 && b == 0 {
+		return 0, 0
+	}
+
+	var r, theta float64
+
+	if math.Abs(a) > math.Abs(b) {
+		r, theta = a, math.Pi/4*(b/a)
+	} else {
+		r, theta = b, math.Pi/2-math.Pi/4*(a/b)
+	}
+
+	return r * math.Cos(theta), r * math.Sin(theta)
+}
+
+func samplePolygon(blades int, rotation, choice, u, v float64) (float64, float64) {
+	sector := minInt(int(choice*float64(blades)), blades-1)
+	step := 2 * math.Pi / float64(blades)
+	a := rotation + float64(sector)*step
+	b := a + step
+
+	if u+v > 1 {
+		u, v = 1-u, 1-v
+	}
+
+	return u*math.Cos(a) + v*math.Cos(b), u*math.Sin(a) + v*math.Sin(b)
+}
+
+func thinLensRay(c Camera, target Tuple, s CameraSample) (Tuple, Tuple) {
+	scale := c.FocalDistance / -target.Z
+	focus := NewPoint(target.X*scale, target.Y*scale, target.Z*scale)
+	lens := NewPoint(s.LensX*c.Aperture, s.LensY*c.Aperture, 0)
+
+	return lens, Normalize(SubTuples(focus, lens))
+}
+
+func AutoFocus(c Camera, w World, px, py int) (float64, bool) {
+	r := RayForPixel(c, px, py)
+	hit := Hit(IntersectWorld(w, r))
+
+	if hit == (Intersection{}) {
+		return 0, false
+	}
+
+	point := MatrixTupleMultiply(c.Transform, Position(r, hit.T))
+
+	return -point.Z, point.Z < 0
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApertureForFStop(t *testing.T) {
+	assert.Equal(t, 0.025, ApertureForFStop(0.1, 2))
+	assert.Equal(t, 0.0, ApertureForFStop(0.1, 0))
+}
+
+func TestApertureSamplesStayInsideShape(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+
+	for i := 0; i < 1000; i++ {
+		x, y := sampleDisk(rng.Float64(), rng.Float64())
+		assert.LessOrEqual(t, math.Hypot(x, y), 1+float64EqualityThreshold)
+
+		x, y = samplePolygon(6, 0.3, rng.Float64(), rng.Float64(), rng.Float64())
+		assert.LessOrEqual(t, math.Hypot(x, y), 1+float64EqualityThreshold)
+
+		angle := math.Mod(math.Atan2(y, x)-0.3+4*math.Pi, math.Pi/3) - math.Pi/6
+		assert.LessOrEqual(t, math.Hypot(x, y)*math.Cos(angle), math.Cos(math.Pi/6)+float64EqualityThreshold)
+	}
+
+	x, y := sampleDisk(0.5, 0.5)
+	assert.Equal(t, 0.0, x)
+	assert.Equal(t, 0.0, y)
+}
+
+func TestThinLensRaysConvergeOnFocalPlane(t *testing.T) {
+	c := NewCamera(11, 11, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+	pinhole := RayForSample(c, 2, 7, PixelCenter)
+
+	c.Aperture = 0.5
+	c.FocalDistance = 5
+
+	focus := Position(pinhole, 5/pinhole.Direction.Z)
+
+	for _, lens := range [][2]float64{{0, 0}, {1, 0}, {-0.3, 0.8}} {
+		s := PixelCenter
+		s.LensX, s.LensY = lens[0], lens[1]
+		r := RayForSample(c, 2, 7, s)
+
+		assert.InDelta(t, -5.0, r.Origin.Z, float64EqualityThreshold)
+		assert.True(t, TupleEquals(focus, Position(r, (focus.Z-r.Origin.Z)/r.Direction.Z)))
+	}
+}
+
+func TestLensCameraSamplesTheAperture(t *testing.T) {
+	c := NewCamera(10, 10, math.Pi/2)
+
+	assert.False(t, c.HasLens())
+	assert.Equal(t, []CameraSample{PixelCenter}, PixelSamples(c, rand.New(rand.NewSource(1))))
+
+	c.Aperture = 0.1
+	c.FocalDistance = 3
+	c.Blades = 5
+	samples := PixelSamples(c, rand.New(rand.NewSource(1)))
+
+	assert.Equal(t, 1, len(samples))
+	assert.NotEqual(t, 0.0, samples[0].LensX)
+}
+
+func TestAutoFocusUsesFirstHitDepth(t *testing.T) {
+	w := NewDefaultWorld()
+	c := NewCamera(11, 11, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	distance, ok := AutoFocus(c, w, 5, 5)
+	assert.True(t, ok)
+	assert.InDelta(t, 4.0, distance, float64EqualityThreshold)
+
+	_, ok = AutoFocus(c, w, 0, 0)
+	assert.False(t, ok)
+}
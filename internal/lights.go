@@ -54,13 +54,13 @@ func (light AreaLight) GetIntensity() Color {
 	return light.Intensity
 }
 
-func (light AreaLight) PointOnLight(u, v int, jitter bool) Tuple {
+func (light AreaLight) PointOnLight(u, v int, rng *rand.Rand) Tuple {
 	var offset Tuple
 
-	if jitter {
+	if rng != nil {
 		offset = AddTuples(
-			TupleScalarMultiply(light.UVec, float64(u)+0.5*rand.Float64()),
-			TupleScalarMultiply(light.VVec, float64(v)+0.5*rand.Float64()),
+			TupleScalarMultiply(light.UVec, float64(u)+0.5*rng.Float64()),
+			TupleScalarMultiply(light.VVec, float64(v)+0.5*rng.Float64()),
 		)
 	} else {
 		offset = AddTuples(
@@ -93,34 +93,37 @@ func NewAreaLight(corner Tuple, uvec Tuple, usteps int, vvec Tuple, vsteps int,
 	}
 }
 
-func Lighting(m Material, object Shape, light LightSource, point, eyeV, normalV Tuple, intensity float64) Color {
-	var color Color
+func Lighting(m Material, object Shape, light LightSource, point, eyeV, normalV Tuple, intensity Color) Color {
+	return LightingAtTime(m, object, light, point, eyeV, normalV, intensity, 0, nil)
+}
 
-	if m.Pattern != nil {
-		color = PatternAtShape(m.Pattern, object, point)
-	} else {
-		color = m.Color
-	}
+func LightingAtTime(m Material, object Shape, light LightSource, point, eyeV, normalV Tuple, intensity Color, time float64, rng *rand.Rand) Color {
+	color := surfaceColor(m, object, point, time)
 
 	switch light.(type) {
 	case AreaLight:
 		l := light.(AreaLight)
 
 		var ambient, diffuse, specular, total Color
+		var jitter *rand.Rand
+
+		if l.Jitter {
+			jitter = rng
+		}
 
 		effectiveColor := HadamardProduct(color, light.GetIntensity())
 		ambient = ColorScalarMultiply(effectiveColor, m.Ambient)
 
 		for u := 0; u < l.USteps; u++ {
 			for v := 0; v < l.VSteps; v++ {
-				lightV := Normalize(SubTuples(l.PointOnLight(u, v, l.Jitter), point))
+				lightV := Normalize(SubTuples(l.PointOnLight(u, v, jitter), point))
 				lightDotNormal := Dot(lightV, normalV)
 
 				if lightDotNormal < 0 {
 					diffuse = NewColor(0, 0, 0)
 					specular = NewColor(0, 0, 0)
 				} else {
-					diffuse = ColorScalarMultiply(effectiveColor, m.Diffuse*lightDotNormal*intensity)
+					diffuse = HadamardProduct(ColorScalarMultiply(effectiveColor, m.Diffuse*lightDotNormal), intensity)
 
 					reflectV := Reflect(Negate(lightV), normalV)
 					reflectDotEye := Dot(reflectV, eyeV)
@@ -129,7 +132,7 @@ func Lighting(m Material, object Shape, light LightSource, point, eyeV, normalV
 						specular = NewColor(0, 0, 0)
 					} else {
 						factor := math.Pow(reflectDotEye, m.Shininess)
-						specular = ColorScalarMultiply(light.GetIntensity(), m.Specular*factor*intensity)
+						specular = HadamardProduct(ColorScalarMultiply(light.GetIntensity(), m.Specular*factor), intensity)
 					}
 				}
 
@@ -137,7 +140,7 @@ func Lighting(m Material, object Shape, light LightSource, point, eyeV, normalV
 			}
 		}
 
-		return AddColors(ambient, ColorScalarMultiply(ColorScalarDivide(total, float64(l.Samples)), intensity))
+		return AddColors(ambient, ColorScalarDivide(total, float64(l.Samples)))
 
 	case PointLight:
 		l := light.(PointLight)
@@ -154,7 +157,7 @@ func Lighting(m Material, object Shape, light LightSource, point, eyeV, normalV
 			diffuse = NewColor(0, 0, 0)
 			specular = NewColor(0, 0, 0)
 		} else {
-			diffuse = ColorScalarMultiply(effectiveColor, m.Diffuse*lightDotNormal*intensity)
+			diffuse = HadamardProduct(ColorScalarMultiply(effectiveColor, m.Diffuse*lightDotNormal), intensity)
 
 			reflectV := Reflect(Negate(lightV), normalV)
 			reflectDotEye := Dot(reflectV, eyeV)
@@ -163,7 +166,7 @@ func Lighting(m Material, object Shape, light LightSource, point, eyeV, normalV
 				specular = NewColor(0, 0, 0)
 			} else {
 				factor := math.Pow(reflectDotEye, m.Shininess)
-				specular = ColorScalarMultiply(light.GetIntensity(), m.Specular*factor*intensity)
+				specular = HadamardProduct(ColorScalarMultiply(light.GetIntensity(), m.Specular*factor), intensity)
 			}
 		}
 		return AddColors(ambient, AddColors(diffuse, specular))
@@ -172,32 +175,31 @@ func Lighting(m Material, object Shape, light LightSource, point, eyeV, normalV
 	}
 }
 
-func IntensityAt(light LightSource, point Tuple, w World) float64 {
+func IntensityAt(light LightSource, point Tuple, w World) Color {
 	switch light.(type) {
 	case PointLight:
-		if IsShadowed(w, light.GetPosition(), point) {
-			return 0.0
-		}
-
-		return 1.0
+		return LightTransmittance(w, light.GetPosition(), point)
 
 	case AreaLight:
 		light := light.(AreaLight)
-		var total float64
+		var total Color
+		var rng *rand.Rand
+
+		if light.Jitter {
+			rng = w.Rand()
+		}
 
 		for v := 0; v < light.VSteps; v++ {
 			for u := 0; u < light.USteps; u++ {
-				lightPos := light.PointOnLight(u, v, light.Jitter)
+				lightPos := light.PointOnLight(u, v, rng)
 
-				if !IsShadowed(w, lightPos, point) {
-					total += 1.0
-				}
+				total = AddColors(total, LightTransmittance(w, lightPos, point))
 			}
 		}
 
-		return total / float64(light.Samples)
+		return ColorScalarDivide(total, float64(light.Samples))
 
 	default:
-		return 0.0
+		return black
 	}
 }
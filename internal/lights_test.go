@@ -2,6 +2,7 @@ package internal
 
 import (
 	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,7 +24,7 @@ func TestLightingEyeBetweenLightAndSurface(t *testing.T) {
 	eyeV := NewVector(0, 0, -1)
 	normalV := NewVector(0, 0, -1)
 	light := NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1))
-	result := Lighting(m, NewSphere(), light, position, eyeV, normalV, 1.0)
+	result := Lighting(m, NewSphere(), light, position, eyeV, normalV, NewColor(1, 1, 1))
 
 	assert.True(t, ColorEquals(NewColor(1.9, 1.9, 1.9), result))
 }
@@ -35,7 +36,7 @@ func TestLightingEyeBetweenLightAndSurfaceLightOffset45(t *testing.T) {
 	eyeV := NewVector(0, 1/math.Sqrt(2), -1/math.Sqrt(2))
 	normalV := NewVector(0, 0, -1)
 	light := NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1))
-	result := Lighting(m, NewSphere(), light, position, eyeV, normalV, 1.0)
+	result := Lighting(m, NewSphere(), light, position, eyeV, normalV, NewColor(1, 1, 1))
 
 	assert.True(t, ColorEquals(NewColor(1.0, 1.0, 1.0), result))
 }
@@ -47,7 +48,7 @@ func TestLightingEyeOppositeSurfaceLightOffset45(t *testing.T) {
 	eyeV := NewVector(0, 0, -1)
 	normalV := NewVector(0, 0, -1)
 	light := NewPointLight(NewPoint(0, 10, -10), NewColor(1, 1, 1))
-	result := Lighting(m, NewSphere(), light, position, eyeV, normalV, 1.0)
+	result := Lighting(m, NewSphere(), light, position, eyeV, normalV, NewColor(1, 1, 1))
 
 	assert.True(t, ColorEquals(NewColor(0.7364, 0.7364, 0.7364), result))
 }
@@ -59,7 +60,7 @@ func TestLightingEyeInPathOfReflectionVector(t *testing.T) {
 	eyeV := NewVector(0, -1/math.Sqrt(2), -1/math.Sqrt(2))
 	normalV := NewVector(0, 0, -1)
 	light := NewPointLight(NewPoint(0, 10, -10), NewColor(1, 1, 1))
-	result := Lighting(m, NewSphere(), light, position, eyeV, normalV, 1.0)
+	result := Lighting(m, NewSphere(), light, position, eyeV, normalV, NewColor(1, 1, 1))
 
 	assert.True(t, ColorEquals(NewColor(1.6364, 1.6364, 1.6364), result))
 }
@@ -71,7 +72,7 @@ func TestLightingLightBehindSurface(t *testing.T) {
 	eyeV := NewVector(0, 0, -1)
 	normalV := NewVector(0, 0, -1)
 	light := NewPointLight(NewPoint(0, 0, 10), NewColor(1, 1, 1))
-	result := Lighting(m, NewSphere(), light, position, eyeV, normalV, 1.0)
+	result := Lighting(m, NewSphere(), light, position, eyeV, normalV, NewColor(1, 1, 1))
 
 	assert.True(t, ColorEquals(NewColor(0.1, 0.1, 0.1), result))
 }
@@ -83,7 +84,7 @@ func LightingWithSurfaceInShadow(t *testing.T) {
 	eyeV := NewVector(0, 0, -1)
 	normalV := NewVector(0, 0, -1)
 	light := NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1))
-	result := Lighting(m, NewSphere(), light, position, eyeV, normalV, 0.0)
+	result := Lighting(m, NewSphere(), light, position, eyeV, normalV, NewColor(0, 0, 0))
 
 	assert.True(t, ColorEquals(NewColor(0.1, 0.1, 0.1), result))
 }
@@ -98,8 +99,8 @@ func TestLightingWithPatternApplied(t *testing.T) {
 	eyeV := NewVector(0, 0, -1)
 	normalV := NewVector(0, 0, -1)
 	light := NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1))
-	c1 := Lighting(m, NewSphere(), light, NewPoint(0.9, 0, 0), eyeV, normalV, 1.0)
-	c2 := Lighting(m, NewSphere(), light, NewPoint(1.1, 0, 0), eyeV, normalV, 1.0)
+	c1 := Lighting(m, NewSphere(), light, NewPoint(0.9, 0, 0), eyeV, normalV, NewColor(1, 1, 1))
+	c2 := Lighting(m, NewSphere(), light, NewPoint(1.1, 0, 0), eyeV, normalV, NewColor(1, 1, 1))
 
 	assert.True(t, ColorEquals(NewColor(1, 1, 1), c1))
 	assert.True(t, ColorEquals(NewColor(0, 0, 0), c2))
@@ -124,10 +125,57 @@ func TestPointLightsEvaluateIntensityAtPoint(t *testing.T) {
 
 	for _, test := range testCases {
 		intensity := IntensityAt(light, test.point, w)
-		assert.InDelta(t, test.result, intensity, float64EqualityThreshold)
+		assert.True(t, ColorEquals(NewColor(test.result, test.result, test.result), intensity))
 	}
 }
 
+func TestIntensityThroughTransparentObjectIsTinted(t *testing.T) {
+	glass := NewGlassSphere()
+	glass.Material.Color = NewColor(1, 0.5, 0.25)
+	glass.Material.Transparency = 0.8
+
+	w := NewWorld()
+	w.Objects = []Shape{glass}
+
+	point := NewPoint(0, 0, 5)
+	light := NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1))
+	assert.True(t, ColorEquals(NewColor(0, 0, 0), IntensityAt(light, point, w)))
+
+	w.TransmissiveShadows = true
+	assert.True(t, ColorEquals(NewColor(0.8, 0.4, 0.2), IntensityAt(light, point, w)))
+
+	glass.Material.Absorption = NewColor(0, 1, 1)
+	glass.Material.AbsorptionDensity = 0.5
+	expected := NewColor(0.8, 0.4*math.Exp(-1), 0.2*math.Exp(-1))
+	assert.True(t, ColorEquals(expected, IntensityAt(light, point, w)))
+
+	area := NewAreaLight(NewPoint(-0.5, -0.5, -10), NewVector(1, 0, 0), 2, NewVector(0, 1, 0), 2, NewColor(1, 1, 1))
+	glass.Material.AbsorptionDensity = 0
+	assert.True(t, ColorEquals(NewColor(0.8, 0.4, 0.2), IntensityAt(area, point, w)))
+
+	opaque := NewSphere()
+	opaque.SetTransform(Translate(0, 0, 3))
+	w.Objects = append(w.Objects, opaque)
+	assert.True(t, ColorEquals(NewColor(0, 0, 0), IntensityAt(light, point, w)))
+}
+
+func TestIntensityFromInsideTransparentObjectAbsorbsToItsExit(t *testing.T) {
+	glass := NewGlassSphere()
+	glass.Material.Color = NewColor(1, 0.5, 0.25)
+	glass.Material.Transparency = 0.8
+	glass.Material.Absorption = NewColor(0, 1, 1)
+	glass.Material.AbsorptionDensity = 0.5
+
+	w := NewWorld()
+	w.Objects = []Shape{glass}
+	w.TransmissiveShadows = true
+
+	light := NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1))
+	expected := NewColor(0.8, 0.4*math.Exp(-0.5), 0.2*math.Exp(-0.5))
+
+	assert.True(t, ColorEquals(expected, IntensityAt(light, NewPoint(0, 0, 0), w)))
+}
+
 func TestLightingUsesIntensityToAttenuateColor(t *testing.T) {
 	testCases := []struct {
 		intensity float64
@@ -154,7 +202,7 @@ func TestLightingUsesIntensityToAttenuateColor(t *testing.T) {
 	normalV := NewVector(0, 0, -1)
 
 	for _, test := range testCases {
-		result := Lighting(shape.GetMaterial(), shape, w.Lights[0], pt, eyeV, normalV, test.intensity)
+		result := Lighting(shape.GetMaterial(), shape, w.Lights[0], pt, eyeV, normalV, NewColor(test.intensity, test.intensity, test.intensity))
 		assert.True(t, ColorEquals(test.result, result))
 	}
 }
@@ -192,7 +240,7 @@ func TestFindSinglePointOnAreaLight(t *testing.T) {
 	light := NewAreaLight(corner, v1, 4, v2, 2, NewColor(1, 1, 1))
 
 	for _, test := range testCases {
-		pt := light.PointOnLight(test.u, test.v, false)
+		pt := light.PointOnLight(test.u, test.v, nil)
 		assert.True(t, TupleEquals(test.result, pt))
 	}
 }
@@ -217,7 +265,7 @@ func TestAreaLightIntensityFunction(t *testing.T) {
 
 	for _, test := range testCases {
 		intensity := IntensityAt(light, test.point, w)
-		assert.InDelta(t, test.result, intensity, float64EqualityThreshold)
+		assert.True(t, ColorEquals(NewColor(test.result, test.result, test.result), intensity))
 	}
 }
 
@@ -250,8 +298,45 @@ func TestLightingFunctionSamplesAreaLight(t *testing.T) {
 		pt := test.point
 		eyeV := Normalize(SubTuples(eye, pt))
 		normalV := NewVector(pt.X, pt.Y, pt.Z)
-		result := Lighting(shape.GetMaterial(), shape, light, pt, eyeV, normalV, 1.0)
+		result := Lighting(shape.GetMaterial(), shape, light, pt, eyeV, normalV, NewColor(1, 1, 1))
 
 		assert.True(t, ColorEquals(test.result, result))
 	}
 }
+
+func TestJitteredAreaLightingUsesTheRandomSource(t *testing.T) {
+	light := NewAreaLight(NewPoint(-0.5, -0.5, -5), NewVector(1, 0, 0), 2, NewVector(0, 1, 0), 2, NewColor(1, 1, 1))
+	shape := NewSphere()
+	m := shape.GetMaterial()
+	m.Specular = 0
+	pt := NewPoint(0, 0, -1)
+	eyeV := NewVector(0, 0, -1)
+	normalV := NewVector(0, 0, -1)
+
+	fixed := Lighting(m, shape, light, pt, eyeV, normalV, NewColor(1, 1, 1))
+	unjittered := LightingAtTime(m, shape, light, pt, eyeV, normalV, NewColor(1, 1, 1), 0, rand.New(rand.NewSource(1)))
+
+	assert.True(t, ColorEquals(fixed, unjittered))
+
+	light.Jitter = true
+	first := LightingAtTime(m, shape, light, pt, eyeV, normalV, NewColor(1, 1, 1), 0, rand.New(rand.NewSource(1)))
+	again := LightingAtTime(m, shape, light, pt, eyeV, normalV, NewColor(1, 1, 1), 0, rand.New(rand.NewSource(1)))
+
+	assert.Equal(t, first, again)
+	assert.NotEqual(t, fixed, first)
+}
+
+func TestAreaLightingScalesLinearlyWithIntensity(t *testing.T) {
+	light := NewAreaLight(NewPoint(-0.5, -0.5, -5), NewVector(1, 0, 0), 2, NewVector(0, 1, 0), 2, NewColor(1, 1, 1))
+	shape := NewSphere()
+	m := shape.GetMaterial()
+	m.Ambient = 0
+	pt := NewPoint(0, 0, -1)
+	eyeV := NewVector(0, 0, -1)
+	normalV := NewVector(0, 0, -1)
+
+	lit := Lighting(m, shape, light, pt, eyeV, normalV, NewColor(1, 1, 1))
+	half := Lighting(m, shape, light, pt, eyeV, normalV, NewColor(0.5, 0.5, 0.5))
+
+	assert.True(t, ColorEquals(ColorScalarMultiply(lit, 0.5), half))
+}
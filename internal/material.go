@@ -1,5 +1,7 @@
 package internal
 
+import "math"
+
 type Material struct {
 	Color           Color
 	Pattern         Pattern
@@ -10,6 +12,16 @@ type Material struct {
 	Reflective      float64
 	Transparency    float64
 	RefractiveIndex float64
+	Medium          *Medium
+
+	Absorption        Color
+	AbsorptionDensity float64
+
+	Dispersion *Dispersion
+
+	Fresnel      FresnelMode
+	ConductorEta Color
+	ConductorK   Color
 }
 
 var DefaultMaterial = NewMaterial(
@@ -35,6 +47,13 @@ func NewDefaultMaterial() Material {
 		DefaultMaterial.Reflective,
 		DefaultMaterial.Transparency,
 		DefaultMaterial.RefractiveIndex,
+		nil,
+		Color{},
+		0,
+		nil,
+		FresnelAuto,
+		Color{},
+		Color{},
 	}
 }
 
@@ -60,6 +79,16 @@ func MaterialEquals(m1, m2 Material) bool {
 		m1.Reflective == m2.Reflective
 }
 
+func (m Material) AbsorptionTransmittance(distance float64) Color {
+	if m.AbsorptionDensity <= 0 || distance <= 0 {
+		return white
+	}
+
+	depth := m.AbsorptionDensity * distance
+
+	return NewColor(math.Exp(-m.Absorption.R*depth), math.Exp(-m.Absorption.G*depth), math.Exp(-m.Absorption.B*depth))
+}
+
 func (m *Material) SetColor(c Color) {
 	m.Color = c
 }
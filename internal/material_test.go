@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -18,3 +19,15 @@ func TestDefaultMaterial(t *testing.T) {
 	assert.InDelta(t, 0.0, m.Transparency, float64EqualityThreshold)
 	assert.InDelta(t, 1.0, m.RefractiveIndex, float64EqualityThreshold)
 }
+
+func TestAbsorptionTransmittance(t *testing.T) {
+	m := NewDefaultMaterial()
+
+	assert.True(t, ColorEquals(NewColor(1, 1, 1), m.AbsorptionTransmittance(3)))
+
+	m.Absorption = NewColor(0, 0.5, 1)
+	m.AbsorptionDensity = 2
+
+	assert.True(t, ColorEquals(NewColor(1, math.Exp(-2), math.Exp(-4)), m.AbsorptionTransmittance(2)))
+	assert.True(t, ColorEquals(NewColor(1, 1, 1), m.AbsorptionTransmittance(0)))
+}
@@ -0,0 +1,394 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+)
+
+type Medium struct {
+	Absorption Color
+	Scattering Color
+	Anisotropy float64
+	Steps      int
+}
+
+func NewMedium(absorption, scattering Color, anisotropy float64) *Medium {
+	return &Medium{
+		Absorption: absorption,
+		Scattering: scattering,
+		Anisotropy: anisotropy,
+		Steps:      32,
+	}
+}
+
+func (m *Medium) Extinction() Color {
+	return AddColors(m.Absorption, m.Scattering)
+}
+
+func (m *Medium) Transmittance(distance float64) Color {
+	sigma := m.Extinction()
+
+	return NewColor(math.Exp(-sigma.R*distance), math.Exp(-sigma.G*distance), math.Exp(-sigma.B*distance))
+}
+
+func (m *Medium) farDistance() float64 {
+	sigma := math.Min(math.Min(m.Extinction().R, m.Extinction().G), m.Extinction().B)
+
+	if sigma <= 0 {
+		return 1e3
+	}
+
+	return math.Log(1e3) / sigma
+}
+
+func HenyeyGreenstein(cosTheta, g float64) float64 {
+	denominator := 1 + g*g - 2*g*cosTheta
+
+	return (1 - g*g) / (4 * math.Pi * denominator * math.Sqrt(denominator))
+}
+
+type participatingMedium interface {
+	transmittance(r Ray, t0, t1 float64, rng *rand.Rand) Color
+	inScattering(w World, r Ray, t0, t1 float64, others func(t float64) Color, rng *rand.Rand) Color
+}
+
+func (m *Medium) transmittance(r Ray, t0, t1 float64, rng *rand.Rand) Color {
+	return m.Transmittance((t1 - t0) * Magnitude(r.Direction))
+}
+
+func (m *Medium) inScattering(w World, r Ray, t0, t1 float64, others func(t float64) Color, rng *rand.Rand) Color {
+	var total Color
+
+	speed := Magnitude(r.Direction)
+	direction := Normalize(r.Direction)
+	steps := maxInt(m.Steps, 1)
+	dt := (t1 - t0) / float64(steps)
+	offset := rng.Float64()
+
+	for k := 0; k < steps; k++ {
+		t := t0 + (float64(k)+offset)*dt
+		scattered := HadamardProduct(m.Scattering, lightScattering(w, Position(r, t), direction, m.Anisotropy, rng))
+		transmittance := HadamardProduct(m.Transmittance((t-t0)*speed), others(t))
+
+		total = AddColors(total, ColorScalarMultiply(HadamardProduct(transmittance, scattered), dt*speed))
+	}
+
+	return total
+}
+
+type mediumLink struct {
+	cached bool
+	owner  Shape
+	medium participatingMedium
+}
+
+func mediumOwner(s Shape) (Shape, participatingMedium) {
+	if link := s.mediumLink(); link.cached {
+		return link.owner, link.medium
+	}
+
+	return findMediumOwner(s)
+}
+
+func cacheMediumOwners(s Shape) {
+	owner, medium := findMediumOwner(s)
+	*s.mediumLink() = mediumLink{true, owner, medium}
+
+	switch t := s.(type) {
+	case *Group:
+		for _, child := range t.Children {
+			cacheMediumOwners(child)
+		}
+	case *CSG:
+		cacheMediumOwners(t.Left)
+		cacheMediumOwners(t.Right)
+	}
+}
+
+func forgetMediumOwners(s Shape) {
+	*s.mediumLink() = mediumLink{}
+
+	switch t := s.(type) {
+	case *Group:
+		for _, child := range t.Children {
+			forgetMediumOwners(child)
+		}
+	case *CSG:
+		if t.Left != nil {
+			forgetMediumOwners(t.Left)
+		}
+		if t.Right != nil {
+			forgetMediumOwners(t.Right)
+		}
+	}
+}
+
+func findMediumOwner(s Shape) (Shape, participatingMedium) {
+	for ; s != nil; s = s.GetParent() {
+		if v, ok := s.(*Volume); ok {
+			return v, v
+		}
+
+		if m := s.GetMaterial().Medium; m != nil {
+			return s, m
+		}
+	}
+
+	return nil, nil
+}
+
+func isMediumBoundary(s Shape) bool {
+	owner, _ := mediumOwner(s)
+	return owner != nil
+}
+
+type mediumSegment struct {
+	T0     float64
+	T1     float64
+	Medium participatingMedium
+}
+
+func mediumSegments(w World, xs Intersections, tMax float64) []mediumSegment {
+	var segments []mediumSegment
+
+	if w.Medium != nil {
+		end := tMax
+
+		if math.IsInf(end, 1) {
+			end = w.Medium.farDistance()
+		}
+
+		segments = append(segments, mediumSegment{0, end, w.Medium})
+	}
+
+	var owners []Shape
+	media := map[int64]participatingMedium{}
+	times := map[int64][]float64{}
+
+	for _, i := range xs {
+		owner, medium := mediumOwner(i.Object)
+
+		if owner == nil {
+			continue
+		}
+
+		if _, ok := times[owner.GetID()]; !ok {
+			owners = append(owners, owner)
+			media[owner.GetID()] = medium
+		}
+
+		times[owner.GetID()] = append(times[owner.GetID()], i.T)
+	}
+
+	for _, owner := range owners {
+		medium := media[owner.GetID()]
+		inside := false
+		start := 0.0
+
+		for _, t := range times[owner.GetID()] {
+			if inside && t > 0 {
+				segments = appendSegment(segments, math.Max(start, 0), math.Min(t, tMax), medium)
+			}
+
+			inside = !inside
+			start = t
+		}
+
+		if inside {
+			segments = appendSegment(segments, math.Max(start, 0), tMax, medium)
+		}
+	}
+
+	return segments
+}
+
+func appendSegment(segments []mediumSegment, t0, t1 float64, m participatingMedium) []mediumSegment {
+	if t1 <= t0 || math.IsInf(t1, 1) {
+		return segments
+	}
+
+	return append(segments, mediumSegment{t0, t1, m})
+}
+
+func segmentTransmittance(segments []mediumSegment, r Ray, t float64, rng *rand.Rand) Color {
+	transmittance := white
+
+	for _, s := range segments {
+		if end := math.Min(t, s.T1); end > s.T0 {
+			transmittance = HadamardProduct(transmittance, s.Medium.transmittance(r, s.T0, end, rng))
+		}
+	}
+
+	return transmittance
+}
+
+func attenuateThroughMedia(w World, r Ray, xs Intersections, tMax float64, color Color) Color {
+	transmittance, scattered := mediaTransport(w, r, xs, tMax)
+
+	return AddColors(HadamardProduct(color, transmittance), scattered)
+}
+
+func mediaTransport(w World, r Ray, xs Intersections, tMax float64) (Color, Color) {
+	segments := mediumSegments(w, xs, tMax)
+
+	if len(segments) == 0 {
+		return white, black
+	}
+
+	rng := w.Rand()
+	end := tMax
+
+	if math.IsInf(end, 1) {
+		end = 0
+
+		for _, s := range segments {
+			end = math.Max(end, s.T1)
+		}
+	}
+
+	transmittance := segmentTransmittance(segments, r, end, rng)
+	var scattered Color
+
+	for i, s := range segments {
+		others := func(t float64) Color {
+			transmittance := white
+
+			for j, o := range segments {
+				if j == i {
+					continue
+				}
+
+				if end := math.Min(t, o.T1); end > o.T0 {
+					transmittance = HadamardProduct(transmittance, o.Medium.transmittance(r, o.T0, end, rng))
+				}
+			}
+
+			return transmittance
+		}
+
+		scattered = AddColors(scattered, s.Medium.inScattering(w, r, s.T0, s.T1, others, rng))
+	}
+
+	return transmittance, scattered
+}
+
+func lightScattering(w World, point, direction Tuple, anisotropy float64, rng *rand.Rand) Color {
+	var total Color
+
+	for _, light := range w.Lights {
+		area, ok := light.(AreaLight)
+
+		if !ok {
+			total = AddColors(total, HadamardProduct(light.GetIntensity(), phasedTransmittance(w, light.GetPosition(), point, direction, anisotropy)))
+			continue
+		}
+
+		var sum Color
+		var jitter *rand.Rand
+
+		if area.Jitter {
+			jitter = rng
+		}
+
+		for v := 0; v < area.VSteps; v++ {
+			for u := 0; u < area.USteps; u++ {
+				sum = AddColors(sum, phasedTransmittance(w, area.PointOnLight(u, v, jitter), point, direction, anisotropy))
+			}
+		}
+
+		total = AddColors(total, HadamardProduct(light.GetIntensity(), ColorScalarDivide(sum, float64(area.Samples))))
+	}
+
+	return total
+}
+
+func phasedTransmittance(w World, lightPos, point, direction Tuple, anisotropy float64) Color {
+	toLight := Normalize(SubTuples(lightPos, point))
+	phase := HenyeyGreenstein(Dot(toLight, direction), anisotropy)
+
+	return ColorScalarMultiply(LightTransmittance(w, lightPos, point), phase)
+}
+
+func LightTransmittance(w World, lightPos, point Tuple) Color {
+	v := SubTuples(lightPos, point)
+	distance := Magnitude(v)
+	r := w.ShadowRay(point, Normalize(v))
+	xs := IntersectWorld(w, r)
+	transmittance := white
+
+	if w.TransmissiveShadows {
+		transmittance = surfaceTransmittance(xs, r, distance)
+	} else if h := Hit(xs); h != (Intersection{}) && h.Object.CastsShadow() && h.T < distance {
+		transmittance = black
+	}
+
+	if maxComponent(transmittance) <= 0 {
+		return black
+	}
+
+	return HadamardProduct(transmittance, segmentTransmittance(mediumSegments(w, xs, distance), r, distance, w.Rand()))
+}
+
+func surfaceTransmittance(xs Intersections, r Ray, distance float64) Color {
+	transmittance := white
+
+	for k, i := range xs {
+		if i.T <= 0 || i.T >= distance || !i.Object.CastsShadow() || isMediumBoundary(i.Object) {
+			continue
+		}
+
+		m := i.Object.GetMaterial()
+
+		if m.Transparency <= 0 {
+			return black
+		}
+
+		start, end := i.T, distance
+
+		if entry := entryOf(xs, k); entry >= 0 {
+			if xs[entry].T > 0 {
+				continue
+			}
+
+			start, end = 0, i.T
+		} else {
+			for _, o := range xs[k+1:] {
+				if o.Object == i.Object {
+					end = math.Min(o.T, distance)
+					break
+				}
+			}
+		}
+
+		filter := ColorScalarMultiply(surfaceColor(m, i.Object, Position(r, i.T), r.Time), m.Transparency)
+		transmittance = HadamardProduct(transmittance, HadamardProduct(filter, m.AbsorptionTransmittance(end-start)))
+	}
+
+	return transmittance
+}
+
+func entryOf(xs Intersections, k int) int {
+	entry := -1
+
+	for j := 0; j < k; j++ {
+		if xs[j].Object != xs[k].Object {
+			continue
+		}
+
+		if entry < 0 {
+			entry = j
+		} else {
+			entry = -1
+		}
+	}
+
+	return entry
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
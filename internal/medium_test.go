@@ -0,0 +1,199 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHenyeyGreensteinIsNormalised(t *testing.T) {
+	assert.InDelta(t, 1/(4*math.Pi), HenyeyGreenstein(0.3, 0), 1e-9)
+
+	for _, g := range []float64{-0.5, 0, 0.3, 0.8} {
+		integral := 0.0
+		n := 2000
+
+		for i := 0; i < n; i++ {
+			cos := -1 + 2*(float64(i)+0.5)/float64(n)
+			integral += HenyeyGreenstein(cos, g) * 2 * math.Pi * 2 / float64(n)
+		}
+
+		assert.InDelta(t, 1.0, integral, 1e-3)
+	}
+
+	assert.True(t, HenyeyGreenstein(1, 0.5) > HenyeyGreenstein(-1, 0.5))
+}
+
+func TestMediumTransmittance(t *testing.T) {
+	m := NewMedium(NewColor(0.1, 0.2, 0.3), NewColor(0.1, 0, 0), 0)
+
+	assert.True(t, ColorEquals(NewColor(math.Exp(-0.4), math.Exp(-0.4), math.Exp(-0.6)), m.Transmittance(2)))
+}
+
+func TestGlobalMediumFadesSurfacesWithDistance(t *testing.T) {
+	w := NewDefaultWorld()
+	m := w.Objects[0].GetMaterial()
+	m.Ambient = 1
+	m.Diffuse = 0
+	m.Specular = 0
+	w.Objects[0].SetMaterial(m)
+
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+	clear := ColorAt(w, r, RecursionDepth)
+
+	w.Medium = NewMedium(NewColor(0.1, 0.1, 0.1), NewColor(0, 0, 0), 0)
+	foggy := ColorAt(w, r, RecursionDepth)
+
+	assert.True(t, ColorEquals(ColorScalarMultiply(clear, math.Exp(-0.4)), foggy))
+}
+
+func mediumTestWorld(volume Shape) (World, Ray) {
+	w := NewWorld()
+	w.Lights = append(w.Lights, NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1)))
+
+	wall := NewPlane()
+	wall.SetTransform(MatrixMultiply(Translate(0, 0, 5), RotateX(math.Pi/2)))
+	wall.Material.Specular = 0
+	w.Objects = append(w.Objects, wall, volume)
+
+	return w, NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+}
+
+func TestShapeInteriorMediumIsInvisibleButAbsorbs(t *testing.T) {
+	sphere := NewSphere()
+	sphere.Material.Medium = NewMedium(NewColor(0.5, 0.5, 0.5), NewColor(0, 0, 0), 0)
+
+	w, r := mediumTestWorld(sphere)
+	xs := IntersectWorld(w, r)
+
+	assert.Equal(t, w.Objects[0], Hit(xs).Object)
+
+	wall := w.Objects[0].GetMaterial()
+	expected := (wall.Ambient + wall.Diffuse*math.Exp(-0.5*2)) * math.Exp(-0.5*2)
+	color := ColorAt(w, r, RecursionDepth)
+
+	assert.InDelta(t, expected, color.R, 1e-4)
+}
+
+func closedTriangleCube() *Group {
+	g := NewGroup()
+	corners := [][3]float64{}
+
+	for _, axis := range []int{0, 1, 2} {
+		for _, sign := range []float64{-1, 1} {
+			corners = corners[:0]
+
+			for _, uv := range [][2]float64{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}} {
+				var p [3]float64
+				p[axis] = sign
+				p[(axis+1)%3] = uv[0]
+				p[(axis+2)%3] = uv[1]
+				corners = append(corners, p)
+			}
+
+			point := func(i int) Tuple { return NewPoint(corners[i][0], corners[i][1], corners[i][2]) }
+			g.AddChild(NewTriangle(point(0), point(1), point(2)))
+			g.AddChild(NewTriangle(point(0), point(2), point(3)))
+		}
+	}
+
+	return g
+}
+
+func TestMeshGroupInteriorMedium(t *testing.T) {
+	mesh := closedTriangleCube()
+	mesh.Material.Medium = NewMedium(NewColor(0.25, 0.25, 0.25), NewColor(0, 0, 0), 0)
+
+	w, _ := mediumTestWorld(mesh)
+	r := NewRay(NewPoint(0.3, -0.5, -5), NewVector(0, 0, 1))
+	xs := IntersectWorld(w, r)
+	segments := mediumSegments(w, xs, Hit(xs).T)
+
+	assert.Equal(t, 1, len(segments))
+	assert.InDelta(t, 4.0, segments[0].T0, 1e-4)
+	assert.InDelta(t, 6.0, segments[0].T1, 1e-4)
+
+	inside := NewRay(NewPoint(0.3, -0.5, 0), NewVector(0, 0, 1))
+	xs = IntersectWorld(w, inside)
+	segments = mediumSegments(w, xs, Hit(xs).T)
+
+	assert.Equal(t, 1, len(segments))
+	assert.InDelta(t, 0.0, segments[0].T0, 1e-4)
+	assert.InDelta(t, 1.0, segments[0].T1, 1e-4)
+}
+
+func TestScatteringMediumShowsLightShafts(t *testing.T) {
+	w := NewWorld()
+	w.Lights = append(w.Lights, NewPointLight(NewPoint(0, 5, 0), NewColor(10, 10, 10)))
+	w.Medium = NewMedium(NewColor(0, 0, 0), NewColor(0.05, 0.05, 0.05), 0.2)
+
+	blocker := NewCube()
+	blocker.SetTransform(MatrixMultiply(Translate(2, 2.5, 0), Scale(1, 0.1, 3)))
+	w.Objects = append(w.Objects, blocker)
+
+	lit := ColorAt(w, NewRay(NewPoint(-4, 0, -20), NewVector(0, 0, 1)), RecursionDepth)
+	shadowed := ColorAt(w, NewRay(NewPoint(4, 0, -20), NewVector(0, 0, 1)), RecursionDepth)
+
+	assert.True(t, lit.R > 0)
+	assert.True(t, shadowed.R < lit.R)
+}
+
+func TestMediumScatteringSamplesAreaLightsLikeShading(t *testing.T) {
+	light := NewAreaLight(NewPoint(-1, 5, -1), NewVector(2, 0, 0), 2, NewVector(0, 0, 2), 2, NewColor(1, 1, 1))
+	w := NewWorld()
+	w.Lights = append(w.Lights, light)
+
+	point, direction := NewPoint(0, 0, 0), NewVector(1, 0, 0)
+	var expected float64
+
+	for v := 0; v < light.VSteps; v++ {
+		for u := 0; u < light.USteps; u++ {
+			toLight := Normalize(SubTuples(light.PointOnLight(u, v, nil), point))
+			expected += HenyeyGreenstein(Dot(toLight, direction), 0.5) / float64(light.Samples)
+		}
+	}
+
+	a := lightScattering(w, point, direction, 0.5, rand.New(rand.NewSource(1)))
+	b := lightScattering(w, point, direction, 0.5, rand.New(rand.NewSource(2)))
+
+	assert.InDelta(t, expected, a.R, 1e-9)
+	assert.True(t, ColorEquals(a, b))
+}
+
+func TestMediumShadowsByTransmittance(t *testing.T) {
+	sphere := NewSphere()
+	sphere.Material.Medium = NewMedium(NewColor(0.5, 0.5, 0.5), NewColor(0, 0, 0), 0)
+
+	w := NewWorld()
+	w.Objects = append(w.Objects, sphere)
+	light := NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1))
+	w.Lights = append(w.Lights, light)
+
+	assert.InDelta(t, math.Exp(-1), IntensityAt(light, NewPoint(0, 0, 5), w).G, 1e-4)
+	assert.InDelta(t, 1.0, IntensityAt(light, NewPoint(5, 0, 5), w).G, 1e-4)
+}
+
+func TestMediumOwnershipIsCachedUntilMaterialChanges(t *testing.T) {
+	g := NewGroup()
+	sphere := NewSphere()
+	g.AddChild(sphere)
+
+	assert.False(t, isMediumBoundary(sphere))
+
+	m := g.GetMaterial()
+	m.Medium = NewMedium(NewColor(0.5, 0.5, 0.5), NewColor(0, 0, 0), 0)
+	g.SetMaterial(m)
+	cacheMediumOwners(g)
+
+	owner, medium := mediumOwner(sphere)
+	assert.Equal(t, Shape(g), owner)
+	assert.Equal(t, m.Medium, medium)
+	assert.True(t, sphere.mediumLink().cached)
+
+	g.SetMaterial(NewDefaultMaterial())
+
+	assert.False(t, sphere.mediumLink().cached)
+	assert.False(t, isMediumBoundary(sphere))
+}
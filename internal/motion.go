@@ -0,0 +1,348 @@
+package internal
+
+import (
+	"math"
+	"sync"
+)
+
+const motionBoundsSteps = 16
+
+type Motion struct {
+	Start Matrix
+	End   Matrix
+
+	start        transformParts
+	end          transformParts
+	startInverse Matrix
+	endInverse   Matrix
+
+	mu          sync.RWMutex
+	boundsValid bool
+	boundsIn    BoundingBox
+	boundsOut   BoundingBox
+}
+
+type transformParts struct {
+	translation Tuple
+	rotation    quaternion
+	stretch     [3][3]float64
+}
+
+type quaternion struct {
+	w, x, y, z float64
+}
+
+func NewMotion(start, end Matrix) *Motion {
+	m := &Motion{
+		Start:        start,
+		End:          end,
+		start:        decomposeTransform(start),
+		end:          decomposeTransform(end),
+		startInverse: MatrixInverse(start),
+		endInverse:   MatrixInverse(end),
+	}
+
+	if quaternionDot(m.start.rotation, m.end.rotation) < 0 {
+		q := m.end.rotation
+		m.end.rotation = quaternion{-q.w, -q.x, -q.y, -q.z}
+	}
+
+	return m
+}
+
+func SetShapeMotion(s Shape, start, end Matrix) {
+	s.SetTransform(start)
+	s.SetMotion(NewMotion(start, end))
+}
+
+func (m *Motion) TransformAt(time float64) Matrix {
+	if time <= 0 {
+		return m.Start
+	}
+
+	if time >= 1 {
+		return m.End
+	}
+
+	linear, translation := m.interpolate(time)
+
+	return affineMatrix(linear, translation)
+}
+
+func (m *Motion) InverseAt(time float64) Matrix {
+	if time <= 0 {
+		return m.startInverse
+	}
+
+	if time >= 1 {
+		return m.endInverse
+	}
+
+	linear, translation := m.interpolate(time)
+	inverse, ok := invert3(linear)
+
+	if !ok {
+		return MatrixInverse(affineMatrix(linear, translation))
+	}
+
+	offset := NewVector(
+		-(inverse[0][0]*translation.X + inverse[0][1]*translation.Y + inverse[0][2]*translation.Z),
+		-(inverse[1][0]*translation.X + inverse[1][1]*translation.Y + inverse[1][2]*translation.Z),
+		-(inverse[2][0]*translation.X + inverse[2][1]*translation.Y + inverse[2][2]*translation.Z),
+	)
+
+	return affineMatrix(inverse, offset)
+}
+
+func (m *Motion) interpolate(time float64) ([3][3]float64, Tuple) {
+	translation := AddTuples(
+		TupleScalarMultiply(m.start.translation, 1-time),
+		TupleScalarMultiply(m.end.translation, time))
+	rotation := slerp(m.start.rotation, m.end.rotation, time).matrix()
+
+	var stretch [3][3]float64
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			stretch[i][j] = (1-time)*m.start.stretch[i][j] + time*m.end.stretch[i][j]
+		}
+	}
+
+	return multiply3(rotation, stretch), translation
+}
+
+func affineMatrix(linear [3][3]float64, translation Tuple) Matrix {
+	return NewMatrix4([]float64{
+		linear[0][0], linear[0][1], linear[0][2], translation.X,
+		linear[1][0], linear[1][1], linear[1][2], translation.Y,
+		linear[2][0], linear[2][1], linear[2][2], translation.Z,
+		0, 0, 0, 1,
+	})
+}
+
+func (m *Motion) RotationAngle() float64 {
+	return 2 * math.Acos(math.Min(quaternionDot(m.start.rotation, m.end.rotation), 1))
+}
+
+func (m *Motion) Bounds(box BoundingBox) BoundingBox {
+	m.mu.RLock()
+
+	if m.boundsValid && m.boundsIn == box {
+		defer m.mu.RUnlock()
+		return m.boundsOut
+	}
+
+	m.mu.RUnlock()
+
+	swept := NewEmptyBoundingBox()
+
+	for i := 0; i <= motionBoundsSteps; i++ {
+		swept.AddBox(TransformBox(box, m.TransformAt(float64(i)/motionBoundsSteps)))
+	}
+
+	if angle := m.RotationAngle(); angle > 0 && box.Min.X <= box.Max.X {
+		radius := math.Max(stretchRadius(m.start.stretch, box), stretchRadius(m.end.stretch, box))
+		pad := radius * angle / motionBoundsSteps / 2
+
+		swept.Min = NewPoint(swept.Min.X-pad, swept.Min.Y-pad, swept.Min.Z-pad)
+		swept.Max = NewPoint(swept.Max.X+pad, swept.Max.Y+pad, swept.Max.Z+pad)
+	}
+
+	m.mu.Lock()
+	m.boundsValid, m.boundsIn, m.boundsOut = true, box, swept
+	m.mu.Unlock()
+
+	return swept
+}
+
+func InverseAt(s Shape, time float64) Matrix {
+	if m := s.GetMotion(); m != nil {
+		return m.InverseAt(time)
+	}
+
+	return s.GetInverse()
+}
+
+func InverseTransposeAt(s Shape, time float64) Matrix {
+	if m := s.GetMotion(); m != nil {
+		return MatrixTranspose(m.InverseAt(time))
+	}
+
+	return s.GetInverseTranspose()
+}
+
+func (c Camera) HasShutter() bool {
+	return c.ShutterClose > c.ShutterOpen
+}
+
+func (c Camera) shutterTime(u float64) float64 {
+	return c.ShutterOpen + u*(c.ShutterClose-c.ShutterOpen)
+}
+
+func decomposeTransform(mat Matrix) transformParts {
+	var linear [3][3]float64
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			linear[i][j] = mat.Get(i, j)
+		}
+	}
+
+	rotation := linear
+
+	for iteration := 0; iteration < 100; iteration++ {
+		inverse, ok := invert3(transpose3(rotation))
+
+		if !ok {
+			break
+		}
+
+		change := 0.0
+
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				next := 0.5 * (rotation[i][j] + inverse[i][j])
+				change = math.Max(change, math.Abs(next-rotation[i][j]))
+				rotation[i][j] = next
+			}
+		}
+
+		if change < 1e-12 {
+			break
+		}
+	}
+
+	if determinant3(rotation) < 0 {
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				rotation[i][j] = -rotation[i][j]
+			}
+		}
+	}
+
+	return transformParts{
+		translation: NewVector(mat.Get(0, 3), mat.Get(1, 3), mat.Get(2, 3)),
+		rotation:    quaternionFromMatrix(rotation),
+		stretch:     multiply3(transpose3(rotation), linear),
+	}
+}
+
+func stretchRadius(stretch [3][3]float64, box BoundingBox) float64 {
+	radius := 0.0
+
+	for _, x := range []float64{box.Min.X, box.Max.X} {
+		for _, y := range []float64{box.Min.Y, box.Max.Y} {
+			for _, z := range []float64{box.Min.Z, box.Max.Z} {
+				var length2 float64
+
+				for i := 0; i < 3; i++ {
+					v := stretch[i][0]*x + stretch[i][1]*y + stretch[i][2]*z
+					length2 += v * v
+				}
+
+				radius = math.Max(radius, math.Sqrt(length2))
+			}
+		}
+	}
+
+	return radius
+}
+
+func quaternionFromMatrix(r [3][3]float64) quaternion {
+	trace := r[0][0] + r[1][1] + r[2][2]
+
+	switch {
+	case trace > 0:
+		s := 2 * math.Sqrt(trace+1)
+		return quaternion{s / 4, (r[2][1] - r[1][2]) / s, (r[0][2] - r[2][0]) / s, (r[1][0] - r[0][1]) / s}
+	case r[0][0] > r[1][1] && r[0][0] > r[2][2]:
+		s := 2 * math.Sqrt(1+r[0][0]-r[1][1]-r[2][2])
+		return quaternion{(r[2][1] - r[1][2]) / s, s / 4, (r[0][1] + r[1][0]) / s, (r[0][2] + r[2][0]) / s}
+	case r[1][1] > r[2][2]:
+		s := 2 * math.Sqrt(1+r[1][1]-r[0][0]-r[2][2])
+		return quaternion{(r[0][2] - r[2][0]) / s, (r[0][1] + r[1][0]) / s, s / 4, (r[1][2] + r[2][1]) / s}
+	default:
+		s := 2 * math.Sqrt(1+r[2][2]-r[0][0]-r[1][1])
+		return quaternion{(r[1][0] - r[0][1]) / s, (r[0][2] + r[2][0]) / s, (r[1][2] + r[2][1]) / s, s / 4}
+	}
+}
+
+func (q quaternion) matrix() [3][3]float64 {
+	return [3][3]float64{
+		{1 - 2*(q.y*q.y+q.z*q.z), 2 * (q.x*q.y - q.w*q.z), 2 * (q.x*q.z + q.w*q.y)},
+		{2 * (q.x*q.y + q.w*q.z), 1 - 2*(q.x*q.x+q.z*q.z), 2 * (q.y*q.z - q.w*q.x)},
+		{2 * (q.x*q.z - q.w*q.y), 2 * (q.y*q.z + q.w*q.x), 1 - 2*(q.x*q.x+q.y*q.y)},
+	}
+}
+
+func quaternionDot(a, b quaternion) float64 {
+	return a.w*b.w + a.x*b.x + a.y*b.y + a.z*b.z
+}
+
+func slerp(a, b quaternion, t float64) quaternion {
+	cos := quaternionDot(a, b)
+	wa, wb := 1-t, t
+
+	if cos < 0.9995 {
+		theta := math.Acos(cos)
+		wa = math.Sin((1-t)*theta) / math.Sin(theta)
+		wb = math.Sin(t*theta) / math.Sin(theta)
+	}
+
+	q := quaternion{wa*a.w + wb*b.w, wa*a.x + wb*b.x, wa*a.y + wb*b.y, wa*a.z + wb*b.z}
+	length := math.Sqrt(quaternionDot(q, q))
+
+	return quaternion{q.w / length, q.x / length, q.y / length, q.z / length}
+}
+
+func multiply3(a, b [3][3]float64) [3][3]float64 {
+	var c [3][3]float64
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				c[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+
+	return c
+}
+
+func transpose3(a [3][3]float64) [3][3]float64 {
+	var t [3][3]float64
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			t[i][j] = a[j][i]
+		}
+	}
+
+	return t
+}
+
+func determinant3(a [3][3]float64) float64 {
+	return a[0][0]*(a[1][1]*a[2][2]-a[1][2]*a[2][1]) -
+		a[0][1]*(a[1][0]*a[2][2]-a[1][2]*a[2][0]) +
+		a[0][2]*(a[1][0]*a[2][1]-a[1][1]*a[2][0])
+}
+
+func invert3(a [3][3]float64) ([3][3]float64, bool) {
+	det := determinant3(a)
+
+	if math.Abs(det) < 1e-12 {
+		return a, false
+	}
+
+	var inverse [3][3]float64
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r0, r1 := (j+1)%3, (j+2)%3
+			c0, c1 := (i+1)%3, (i+2)%3
+			inverse[i][j] = (a[r0][c0]*a[r1][c1] - a[r0][c1]*a[r1][c0]) / det
+		}
+	}
+
+	return inverse, true
+}
@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMotionEndpointsMatchTransforms(t *testing.T) {
+	start := Translate(1, 2, 3)
+	end := MatrixMultiply(Translate(-1, 0, 2), RotateY(1))
+	m := NewMotion(start, end)
+
+	assert.True(t, MatrixEquals(start, m.TransformAt(0)))
+	assert.True(t, MatrixEquals(end, m.TransformAt(1)))
+	assert.True(t, MatrixEquals(start, m.TransformAt(-0.5)))
+	assert.True(t, MatrixEquals(end, m.TransformAt(1.5)))
+}
+
+func TestMotionInterpolatesTranslationLinearly(t *testing.T) {
+	m := NewMotion(Translate(0, 0, 0), Translate(4, -2, 0))
+
+	assert.True(t, MatrixEquals(Translate(1, -0.5, 0), m.TransformAt(0.25)))
+}
+
+func TestMotionBlendsRotationWithoutShrinking(t *testing.T) {
+	m := NewMotion(Scale(2, 2, 2), MatrixMultiply(RotateZ(math.Pi/2), Scale(2, 2, 2)))
+
+	assert.True(t, MatrixEquals(MatrixMultiply(RotateZ(math.Pi/4), Scale(2, 2, 2)), m.TransformAt(0.5)))
+	assert.InDelta(t, math.Pi/2, m.RotationAngle(), float64EqualityThreshold)
+}
+
+func TestMotionInterpolatesFullTransforms(t *testing.T) {
+	start := MatrixMultiply(Translate(0, 1, 0), MatrixMultiply(RotateX(0.2), Scale(1, 2, 1)))
+	end := MatrixMultiply(Translate(3, 1, 0), MatrixMultiply(RotateX(1.4), Scale(1, 2, 1)))
+	m := NewMotion(start, end)
+
+	expected := MatrixMultiply(Translate(1.5, 1, 0), MatrixMultiply(RotateX(0.8), Scale(1, 2, 1)))
+	assert.True(t, MatrixEquals(expected, m.TransformAt(0.5)))
+}
+
+func TestMotionInverseAt(t *testing.T) {
+	m := NewMotion(Translate(1, 0, 0), MatrixMultiply(Translate(0, 3, 0), MatrixMultiply(RotateY(2), Scale(1, 3, 0.5))))
+
+	for _, time := range []float64{0, 0.3, 0.7, 1} {
+		assert.True(t, MatrixEquals(NewIdentity4(), MatrixMultiply(m.InverseAt(time), m.TransformAt(time))))
+	}
+}
+
+func TestMovingSphereIsHitAlongItsPath(t *testing.T) {
+	s := NewSphere()
+	SetShapeMotion(s, Translate(0, 0, 0), Translate(4, 0, 0))
+
+	r := NewRay(NewPoint(4, 0, -5), NewVector(0, 0, 1))
+	assert.Len(t, Intersect(s, r), 0)
+
+	r.Time = 1
+	assert.Len(t, Intersect(s, r), 2)
+
+	r.Origin = NewPoint(2, 0, -5)
+	r.Time = 0.5
+	xs := Intersect(s, r)
+	assert.Len(t, xs, 2)
+	assert.InDelta(t, 4.0, xs[0].T, float64EqualityThreshold)
+}
+
+func TestMovingSphereNormalFollowsTime(t *testing.T) {
+	s := NewSphere()
+	SetShapeMotion(s, Translate(0, 0, 0), Translate(4, 0, 0))
+
+	n := NormalAtTime(s, NewPoint(3, 0, 0), Intersection{}, 1)
+
+	assert.True(t, TupleEquals(NewVector(-1, 0, 0), n))
+}
+
+func TestMovingPatternFollowsShape(t *testing.T) {
+	s := NewSphere()
+	SetShapeMotion(s, Translate(0, 0, 0), Translate(1, 0, 0))
+	pattern := NewStripePattern(white, black)
+
+	assert.Equal(t, white, PatternAtShapeAtTime(pattern, s, NewPoint(1.5, 0, 0), 1))
+	assert.Equal(t, black, PatternAtShapeAtTime(pattern, s, NewPoint(1.5, 0, 0), 0))
+}
+
+func TestSweptBoundsCoverTranslation(t *testing.T) {
+	s := NewSphere()
+	SetShapeMotion(s, Translate(0, 0, 0), Translate(5, 0, 0))
+
+	box := ParentSpaceBoundsOf(s)
+
+	assert.True(t, TupleEquals(NewPoint(-1, -1, -1), box.Min))
+	assert.True(t, TupleEquals(NewPoint(6, 1, 1), box.Max))
+}
+
+func TestSweptBoundsCoverRotation(t *testing.T) {
+	c := NewCube()
+	m := NewMotion(NewIdentity4(), RotateY(math.Pi/2))
+	c.SetMotion(m)
+
+	box := ParentSpaceBoundsOf(c)
+
+	for i := 0; i <= 100; i++ {
+		swept := TransformBox(BoundsOf(c), m.TransformAt(float64(i)/100))
+		assert.True(t, box.ContainsBox(swept), "t=%d", i)
+	}
+
+	assert.GreaterOrEqual(t, box.Max.X, math.Sqrt2)
+}
+
+func TestGroupBoundsIncludeMovingChildren(t *testing.T) {
+	g := NewGroup()
+	s := NewSphere()
+	SetShapeMotion(s, Translate(-3, 0, 0), Translate(3, 0, 0))
+	g.AddChild(s)
+
+	box := BoundsOf(g)
+
+	assert.True(t, TupleEquals(NewPoint(-4, -1, -1), box.Min))
+	assert.True(t, TupleEquals(NewPoint(4, 1, 1), box.Max))
+
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+	r.Time = 0.5
+	assert.Len(t, Intersect(g, r), 2)
+}
+
+func TestCameraRayTimeFollowsShutter(t *testing.T) {
+	c := NewCamera(11, 11, math.Pi/2)
+	c.ShutterOpen, c.ShutterClose = 0.2, 0.6
+
+	r, ok := CameraRay(c, 5, 5, CameraSample{X: 0.5, Y: 0.5, Time: 0.5})
+
+	assert.True(t, ok)
+	assert.InDelta(t, 0.4, r.Time, float64EqualityThreshold)
+	assert.True(t, c.HasShutter())
+}
+
+func TestPixelSamplesSpreadAcrossShutter(t *testing.T) {
+	c := NewCamera(11, 11, math.Pi/2)
+	c.Samples = 4
+	c.ShutterClose = 1
+
+	samples := PixelSamples(c, rand.New(rand.NewSource(1)))
+	strata := map[int]bool{}
+
+	for _, s := range samples {
+		assert.True(t, s.Time >= 0 && s.Time < 1)
+		strata[int(s.Time*4)] = true
+	}
+
+	assert.Len(t, strata, 4)
+
+	c.Samples = 1
+	assert.Len(t, PixelSamples(c, rand.New(rand.NewSource(1))), 1)
+	assert.NotEqual(t, PixelCenter, PixelSamples(c, rand.New(rand.NewSource(1)))[0])
+}
+
+func TestMotionBlurAveragesPositions(t *testing.T) {
+	w := NewWorld()
+	w.Lights = []LightSource{NewPointLight(NewPoint(0, 0, -10), white)}
+	s := NewSphere()
+	s.Material.Ambient = 1
+	s.Material.Diffuse = 0
+	s.Material.Specular = 0
+	SetShapeMotion(s, Translate(-10, 0, 0), Translate(10, 0, 0))
+	w.Objects = []Shape{s}
+
+	c := NewCamera(1, 1, 0.1)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+	c.Samples = 64
+	c.ShutterClose = 1
+
+	opts := DefaultRenderOptions()
+	opts.Workers = 1
+	image, _, err := RenderPasses(context.Background(), c, w, opts)
+
+	assert.Nil(t, err)
+	assert.InDelta(t, 0.1, image.GetColorAtPixel(0, 0).R, 0.05)
+}
@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+)
+
+const perlinNoiseBound = 1.05
+
+var noisePermutation = func() [512]int {
+	var p [512]int
+
+	for i, v := range rand.New(rand.NewSource(1)).Perm(256) {
+		p[i] = v
+		p[i+256] = v
+	}
+
+	return p
+}()
+
+func PerlinNoise(x, y, z float64) float64 {
+	xf, yf, zf := math.Floor(x), math.Floor(y), math.Floor(z)
+	xi, yi, zi := int(xf)&255, int(yf)&255, int(zf)&255
+	x, y, z = x-xf, y-yf, z-zf
+	u, v, w := fade(x), fade(y), fade(z)
+
+	p := &noisePermutation
+	a := p[xi] + yi
+	aa, ab := p[a]+zi, p[a+1]+zi
+	b := p[xi+1] + yi
+	ba, bb := p[b]+zi, p[b+1]+zi
+
+	return lerp(w,
+		lerp(v,
+			lerp(u, grad(p[aa], x, y, z), grad(p[ba], x-1, y, z)),
+			lerp(u, grad(p[ab], x, y-1, z), grad(p[bb], x-1, y-1, z))),
+		lerp(v,
+			lerp(u, grad(p[aa+1], x, y, z-1), grad(p[ba+1], x-1, y, z-1)),
+			lerp(u, grad(p[ab+1], x, y-1, z-1), grad(p[bb+1], x-1, y-1, z-1))))
+}
+
+func FractalNoise(point Tuple, octaves int) float64 {
+	total, amplitude, frequency := 0.0, 1.0, 1.0
+
+	for i := 0; i < octaves; i++ {
+		total += amplitude * PerlinNoise(point.X*frequency, point.Y*frequency, point.Z*frequency)
+		amplitude /= 2
+		frequency *= 2
+	}
+
+	return total
+}
+
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func lerp(t, a, b float64) float64 {
+	return a + t*(b-a)
+}
+
+func grad(hash int, x, y, z float64) float64 {
+	h := hash & 15
+	u, v := y, z
+
+	if h < 8 {
+		u = x
+	}
+
+	if h < 4 {
+		v = y
+	} else if h == 12 || h == 14 {
+		v = x
+	}
+
+	if h&1 != 0 {
+		u = -u
+	}
+
+	if h&2 != 0 {
+		v = -v
+	}
+
+	return u + v
+}
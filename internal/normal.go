@@ -1,10 +1,14 @@
 package internal
 
 func NormalAt(s Shape, worldPoint Tuple, hit Intersection) Tuple {
-	localPoint := WorldToObject(s, worldPoint)
+	return NormalAtTime(s, worldPoint, hit, 0)
+}
+
+func NormalAtTime(s Shape, worldPoint Tuple, hit Intersection, time float64) Tuple {
+	localPoint := WorldToObjectAtTime(s, worldPoint, time)
 	localNormal := s.LocalNormalAt(localPoint, hit)
 
-	return NormalToWorld(s, localNormal)
+	return NormalToWorldAtTime(s, localNormal, time)
 }
 
 func Reflect(in, normal Tuple) Tuple {
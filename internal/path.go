@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+)
+
+type PathIntegrator struct {
+	MaxBounces    int
+	RouletteStart int
+}
+
+func (p PathIntegrator) Li(w World, r Ray, rng *rand.Rand) Color {
+	var radiance Color
+	throughput := white
+	ray := r
+
+	for bounce := 0; bounce < p.MaxBounces; bounce++ {
+		xs := IntersectWorld(w, ray)
+		hit := Hit(xs)
+
+		if hit == (Intersection{}) {
+			background := attenuateThroughMedia(w, ray, xs, math.Inf(1), w.BackgroundColor(ray))
+			radiance = AddColors(radiance, HadamardProduct(throughput, background))
+			break
+		}
+
+		transmittance, scattered := mediaTransport(w, ray, xs, hit.T)
+		radiance = AddColors(radiance, HadamardProduct(throughput, scattered))
+		throughput = HadamardProduct(throughput, transmittance)
+
+		comps := PrepareComputations(hit, ray, xs)
+		material := fresnelShadingMaterial(comps)
+
+		radiance = AddColors(radiance, HadamardProduct(throughput, directLighting(w, comps)))
+
+		next, weight, ok := scatter(comps, material, rng)
+
+		if !ok {
+			break
+		}
+
+		throughput = HadamardProduct(throughput, weight)
+
+		if bounce >= p.RouletteStart {
+			survival := math.Min(maxComponent(throughput), 0.95)
+
+			if survival <= 0 || rng.Float64() >= survival {
+				break
+			}
+
+			throughput = ColorScalarDivide(throughput, survival)
+		}
+
+		ray = next
+	}
+
+	return radiance
+}
+
+func directLighting(w World, comps Computation) Color {
+	material := fresnelShadingMaterial(comps)
+	material.Ambient = 0
+
+	var color Color
+
+	for _, light := range w.Lights {
+		color = AddColors(color, LightingAtTime(
+			material,
+			comps.Object,
+			light,
+			comps.OverPoint,
+			comps.EyeV,
+			comps.NormalV,
+			IntensityAt(light, comps.OverPoint, w),
+			comps.Time,
+			w.Rand(),
+		))
+	}
+
+	return color
+}
+
+func scatter(comps Computation, m Material, rng *rand.Rand) (Ray, Color, bool) {
+	reflectance, transmittance := FresnelTerms(comps)
+
+	diffuse := ColorScalarMultiply(surfaceColor(m, comps.Object, comps.OverPoint, comps.Time), m.Diffuse)
+	weights := []float64{
+		maxComponent(diffuse),
+		m.Reflective * meanComponent(reflectance),
+		m.Transparency * meanComponent(transmittance),
+	}
+	total := weights[0] + weights[1] + weights[2]
+
+	if total <= 0 {
+		return Ray{}, Color{}, false
+	}
+
+	choice := rng.Float64() * total
+
+	switch {
+	case choice < weights[0]:
+		direction := cosineSampleHemisphere(comps.NormalV, rng)
+		return SpawnRay(comps, comps.OverPoint, direction), ColorScalarDivide(diffuse, weights[0]/total), true
+
+	case choice < weights[0]+weights[1]:
+		tint := ColorScalarDivide(reflectance, meanComponent(reflectance))
+		return SpawnRay(comps, comps.OverPoint, comps.ReflectV), ColorScalarMultiply(tint, total), true
+
+	default:
+		direction, ok := refractDirection(comps)
+
+		if !ok {
+			return SpawnRay(comps, comps.OverPoint, comps.ReflectV), ColorScalarMultiply(white, total), true
+		}
+
+		weight := ColorScalarMultiply(ColorScalarDivide(transmittance, meanComponent(transmittance)), total)
+
+		if !comps.Inside {
+			weight = HadamardProduct(weight, m.AbsorptionTransmittance(comps.Thickness))
+		}
+
+		return SpawnRay(comps, comps.UnderPoint, direction), weight, true
+	}
+}
+
+func refractDirection(comps Computation) (Tuple, bool) {
+	indexRatio := comps.N1 / comps.N2
+	cosI := Dot(comps.EyeV, comps.NormalV)
+	sin2T := (indexRatio * indexRatio) * (1 - cosI*cosI)
+
+	if sin2T > 1 {
+		return Tuple{}, false
+	}
+
+	cosT := math.Sqrt(1.0 - sin2T)
+
+	return SubTuples(
+		TupleScalarMultiply(comps.NormalV, indexRatio*cosI-cosT),
+		TupleScalarMultiply(comps.EyeV, indexRatio),
+	), true
+}
+
+func surfaceColor(m Material, object Shape, point Tuple, time float64) Color {
+	if m.Pattern != nil {
+		return PatternAtShapeAtTime(m.Pattern, object, point, time)
+	}
+
+	return m.Color
+}
+
+func cosineSampleHemisphere(normal Tuple, rng *rand.Rand) Tuple {
+	r := math.Sqrt(rng.Float64())
+	phi := 2 * math.Pi * rng.Float64()
+	x, y := r*math.Cos(phi), r*math.Sin(phi)
+	z := math.Sqrt(math.Max(0, 1-x*x-y*y))
+
+	tangent, bitangent := orthonormalBasis(normal)
+
+	return Normalize(AddTuples(
+		AddTuples(TupleScalarMultiply(tangent, x), TupleScalarMultiply(bitangent, y)),
+		TupleScalarMultiply(normal, z),
+	))
+}
+
+func orthonormalBasis(n Tuple) (Tuple, Tuple) {
+	var helper Tuple
+
+	if math.Abs(n.X) > 0.9 {
+		helper = NewVector(0, 1, 0)
+	} else {
+		helper = NewVector(1, 0, 0)
+	}
+
+	tangent := Normalize(Cross(helper, n))
+	bitangent := Cross(n, tangent)
+
+	return tangent, bitangent
+}
+
+func maxComponent(c Color) float64 {
+	return math.Max(math.Max(c.R, c.G), c.B)
+}
+
+func meanComponent(c Color) float64 {
+	return (c.R + c.G + c.B) / 3
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testPathIntegrator = PathIntegrator{MaxBounces: 16, RouletteStart: 3}
+
+func TestCosineSamplesLieInHemisphere(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	normal := Normalize(NewVector(1, 2, -1))
+
+	for i := 0; i < 200; i++ {
+		d := cosineSampleHemisphere(normal, rng)
+
+		assert.InDelta(t, 1.0, Magnitude(d), float64EqualityThreshold)
+		assert.True(t, Dot(d, normal) >= 0)
+	}
+}
+
+func TestPathTraceWithNothingToBounceOffIsDirectLight(t *testing.T) {
+	w := NewWorld()
+	w.Lights = append(w.Lights, NewPointLight(NewPoint(-10, 10, -10), NewColor(1, 1, 1)))
+	s := NewSphere()
+	w.Objects = append(w.Objects, s)
+
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+	m := s.GetMaterial()
+	m.Ambient = 0
+	expected := Lighting(m, s, w.Lights[0], NewPoint(0, 0, -1), NewVector(0, 0, -1), NewVector(0, 0, -1), NewColor(1, 1, 1))
+
+	for seed := int64(0); seed < 10; seed++ {
+		result := testPathIntegrator.Li(w, r, rand.New(rand.NewSource(seed)))
+		assert.True(t, ColorEquals(expected, result))
+	}
+}
+
+func TestPathTraceMissIsBlack(t *testing.T) {
+	w := NewDefaultWorld()
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 1, 0))
+
+	assert.True(t, ColorEquals(black, testPathIntegrator.Li(w, r, rand.New(rand.NewSource(1)))))
+}
+
+func TestPathTraceBleedsColorFromNearbyWall(t *testing.T) {
+	w := NewWorld()
+	w.Lights = append(w.Lights, NewPointLight(NewPoint(-2, 5, -5), NewColor(1, 1, 1)))
+
+	floor := NewPlane()
+	floor.Material.Specular = 0
+	wall := NewPlane()
+	wall.SetTransform(MatrixMultiply(Translate(1, 0, 0), RotateZ(math.Pi/2)))
+	wall.Material.SetColor(NewColor(1, 0, 0))
+	wall.Material.Specular = 0
+	w.Objects = append(w.Objects, floor, wall)
+
+	r := NewRay(NewPoint(0.8, 1, -1), Normalize(NewVector(0, -1, 1)))
+	rng := rand.New(rand.NewSource(42))
+
+	var sum Color
+	n := 400
+
+	for i := 0; i < n; i++ {
+		sum = AddColors(sum, testPathIntegrator.Li(w, r, rng))
+	}
+
+	average := ColorScalarDivide(sum, float64(n))
+	whitted := ColorAt(w, r, RecursionDepth)
+
+	assert.InDelta(t, whitted.R, whitted.G, float64EqualityThreshold)
+	assert.True(t, average.R > average.G+0.05)
+}
+
+func TestPathTracedRenderIsSelectable(t *testing.T) {
+	w := NewDefaultWorld()
+	c := NewCamera(11, 11, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	opts := DefaultRenderOptions()
+	opts.Integrator, _ = NewIntegrator("path", opts)
+	image := RenderWithOptions(c, w, opts)
+
+	whitted := Render(c, w)
+
+	assert.False(t, ColorEquals(whitted.GetColorAtPixel(5, 5), image.GetColorAtPixel(5, 5)))
+	assert.True(t, image.GetColorAtPixel(5, 5).G > 0)
+}
+
+func TestPathTraceAttenuatesThroughMedia(t *testing.T) {
+	w := NewWorld()
+	w.Background = NewSolidBackground(white)
+	sphere := NewSphere()
+	sphere.Material.Medium = NewMedium(NewColor(0.5, 0.5, 0.5), NewColor(0, 0, 0), 0)
+	w.Objects = append(w.Objects, sphere)
+
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+	result := testPathIntegrator.Li(w, r, rand.New(rand.NewSource(1)))
+
+	assert.InDelta(t, math.Exp(-1), result.G, 1e-4)
+
+	w.Medium = NewMedium(NewColor(0.1, 0.1, 0.1), NewColor(0, 0, 0), 0)
+	result = testPathIntegrator.Li(w, r, rand.New(rand.NewSource(1)))
+
+	assert.True(t, ColorEquals(ColorAt(w, r, RecursionDepth), result))
+}
@@ -13,7 +13,11 @@ type Pattern interface {
 }
 
 func PatternAtShape(pattern Pattern, shape Shape, worldPoint Tuple) Color {
-	objectPoint := WorldToObject(shape, worldPoint)
+	return PatternAtShapeAtTime(pattern, shape, worldPoint, 0)
+}
+
+func PatternAtShapeAtTime(pattern Pattern, shape Shape, worldPoint Tuple, time float64) Color {
+	objectPoint := WorldToObjectAtTime(shape, worldPoint, time)
 	patternPoint := MatrixTupleMultiply(pattern.GetInverse(), objectPoint)
 
 	return pattern.PatternAt(patternPoint)
@@ -0,0 +1,250 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const maxPhotonBounces = 8
+
+type Photon struct {
+	Position  Tuple
+	Direction Tuple
+	Power     Color
+}
+
+type PhotonMap struct {
+	Radius  float64
+	photons []Photon
+	axes    []int
+}
+
+func NewPhotonMap(photons []Photon, radius float64) *PhotonMap {
+	m := &PhotonMap{
+		Radius:  radius,
+		photons: append([]Photon(nil), photons...),
+		axes:    make([]int, len(photons)),
+	}
+
+	m.build(0, len(m.photons))
+
+	return m
+}
+
+func (m *PhotonMap) Len() int {
+	return len(m.photons)
+}
+
+func (m *PhotonMap) build(lo, hi int) {
+	if hi-lo < 1 {
+		return
+	}
+
+	axis := widestAxis(m.photons[lo:hi])
+	segment := m.photons[lo:hi]
+
+	sort.Slice(segment, func(i, j int) bool {
+		return tupleAxis(segment[i].Position, axis) < tupleAxis(segment[j].Position, axis)
+	})
+
+	mid := (lo + hi) / 2
+	m.axes[mid] = axis
+
+	m.build(lo, mid)
+	m.build(mid+1, hi)
+}
+
+func (m *PhotonMap) Gather(point Tuple, radius float64) []Photon {
+	var found []Photon
+
+	m.gather(0, len(m.photons), point, radius*radius, &found)
+
+	return found
+}
+
+func (m *PhotonMap) gather(lo, hi int, point Tuple, radius2 float64, found *[]Photon) {
+	if hi-lo < 1 {
+		return
+	}
+
+	mid := (lo + hi) / 2
+	photon := m.photons[mid]
+	offset := SubTuples(photon.Position, point)
+
+	if Dot(offset, offset) <= radius2 {
+		*found = append(*found, photon)
+	}
+
+	delta := tupleAxis(point, m.axes[mid]) - tupleAxis(photon.Position, m.axes[mid])
+
+	if delta <= 0 || delta*delta <= radius2 {
+		m.gather(lo, mid, point, radius2, found)
+	}
+
+	if delta >= 0 || delta*delta <= radius2 {
+		m.gather(mid+1, hi, point, radius2, found)
+	}
+}
+
+func widestAxis(photons []Photon) int {
+	min := NewPoint(math.Inf(1), math.Inf(1), math.Inf(1))
+	max := NewPoint(math.Inf(-1), math.Inf(-1), math.Inf(-1))
+
+	for _, p := range photons {
+		min = NewPoint(math.Min(min.X, p.Position.X), math.Min(min.Y, p.Position.Y), math.Min(min.Z, p.Position.Z))
+		max = NewPoint(math.Max(max.X, p.Position.X), math.Max(max.Y, p.Position.Y), math.Max(max.Z, p.Position.Z))
+	}
+
+	extent := SubTuples(max, min)
+
+	switch {
+	case extent.X >= extent.Y && extent.X >= extent.Z:
+		return 0
+	case extent.Y >= extent.Z:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func tupleAxis(t Tuple, axis int) float64 {
+	switch axis {
+	case 0:
+		return t.X
+	case 1:
+		return t.Y
+	default:
+		return t.Z
+	}
+}
+
+func BuildCausticMap(ctx context.Context, w World, count int, radius float64, rng *rand.Rand) (*PhotonMap, error) {
+	if count < 1 || len(w.Lights) == 0 {
+		return NewPhotonMap(nil, radius), nil
+	}
+
+	w = w.WithRand(rng)
+	perLight := maxInt(count/len(w.Lights), 1)
+
+	var photons []Photon
+
+	for _, light := range w.Lights {
+		power := ColorScalarMultiply(light.GetIntensity(), 4*math.Pi/float64(perLight))
+
+		for i := 0; i < perLight; i++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			origin := light.GetPosition()
+
+			if area, ok := light.(AreaLight); ok {
+				origin = area.PointOnLight(rng.Intn(area.USteps), rng.Intn(area.VSteps), rng)
+			}
+
+			photons = tracePhoton(w, NewRay(origin, uniformSampleSphere(rng)), power, rng, photons)
+		}
+	}
+
+	return NewPhotonMap(photons, radius), nil
+}
+
+func tracePhoton(w World, r Ray, power Color, rng *rand.Rand, photons []Photon) []Photon {
+	falloff := 1.0
+	specular := false
+
+	// Lights have no inverse-square falloff when shading, so undo it up to the first surface to match direct lighting.
+	for bounce := 0; bounce < maxPhotonBounces; bounce++ {
+		xs := IntersectWorld(w, r)
+		hit := Hit(xs)
+
+		if hit == (Intersection{}) {
+			return photons
+		}
+
+		comps := PrepareComputations(hit, r, xs)
+		m := comps.Object.GetMaterial()
+		if bounce == 0 {
+			d := hit.T * Magnitude(r.Direction)
+			falloff = d * d
+		}
+
+		if specular && !comps.Inside && m.Diffuse > 0 {
+			photons = append(photons, Photon{
+				Position:  comps.Point,
+				Direction: Normalize(r.Direction),
+				Power:     ColorScalarMultiply(power, falloff),
+			})
+		}
+
+		fr, ft := FresnelTerms(comps)
+		reflectance := m.Reflective * meanComponent(fr)
+		transmittance := m.Transparency * meanComponent(ft)
+		choice := rng.Float64()
+
+		switch {
+		case choice < reflectance:
+			power = HadamardProduct(power, ColorScalarDivide(fr, meanComponent(fr)))
+			r = SpawnRay(comps, comps.OverPoint, comps.ReflectV)
+
+		case choice < reflectance+transmittance:
+			direction, ok := refractDirection(comps)
+
+			if !ok {
+				r = SpawnRay(comps, comps.OverPoint, comps.ReflectV)
+				break
+			}
+
+			power = HadamardProduct(power, ColorScalarDivide(ft, meanComponent(ft)))
+
+			if !comps.Inside {
+				power = HadamardProduct(power, m.AbsorptionTransmittance(comps.Thickness))
+			}
+
+			r = SpawnRay(comps, comps.UnderPoint, direction)
+
+		default:
+			return photons
+		}
+
+		specular = true
+	}
+
+	return photons
+}
+
+func CausticLighting(w World, comps Computation) Color {
+	caustics := w.Caustics
+
+	if caustics == nil || caustics.Len() == 0 || caustics.Radius <= 0 {
+		return black
+	}
+
+	m := comps.Object.GetMaterial()
+
+	if m.Diffuse <= 0 {
+		return black
+	}
+
+	var flux Color
+
+	for _, p := range caustics.Gather(comps.Point, caustics.Radius) {
+		if Dot(p.Direction, comps.NormalV) < 0 {
+			flux = AddColors(flux, p.Power)
+		}
+	}
+
+	irradiance := ColorScalarDivide(flux, math.Pi*caustics.Radius*caustics.Radius)
+
+	return HadamardProduct(ColorScalarMultiply(surfaceColor(m, comps.Object, comps.Point, comps.Time), m.Diffuse), irradiance)
+}
+
+func uniformSampleSphere(rng *rand.Rand) Tuple {
+	z := 1 - 2*rng.Float64()
+	r := math.Sqrt(math.Max(0, 1-z*z))
+	phi := 2 * math.Pi * rng.Float64()
+
+	return NewVector(r*math.Cos(phi), r*math.Sin(phi), z)
+}
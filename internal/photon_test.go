@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhotonMapGatherMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	photons := make([]Photon, 500)
+
+	for i := range photons {
+		photons[i] = Photon{Position: NewPoint(rng.Float64()*4-2, rng.Float64()*4-2, rng.Float64()*4-2)}
+	}
+
+	m := NewPhotonMap(photons, 0.5)
+	assert.Equal(t, 500, m.Len())
+
+	for _, point := range []Tuple{NewPoint(0, 0, 0), NewPoint(1, -1, 0.5), NewPoint(3, 3, 3)} {
+		expected := 0
+
+		for _, p := range photons {
+			if Magnitude(SubTuples(p.Position, point)) <= 0.5 {
+				expected++
+			}
+		}
+
+		assert.Equal(t, expected, len(m.Gather(point, 0.5)))
+	}
+}
+
+func causticWorld() World {
+	floor := NewPlane()
+	floor.SetTransform(Translate(0, -1, 0))
+
+	glass := NewGlassSphere()
+	glass.SetTransform(Translate(0, 2, 0))
+
+	w := NewWorld()
+	w.Lights = []LightSource{NewPointLight(NewPoint(0, 10, 0), NewColor(1, 1, 1))}
+	w.Objects = []Shape{floor, glass}
+
+	return w
+}
+
+func TestCausticPhotonsAreStoredOnlyAfterSpecularBounces(t *testing.T) {
+	w := causticWorld()
+	m, err := BuildCausticMap(context.Background(), w, 20000, 0.2, rand.New(rand.NewSource(1)))
+
+	assert.Nil(t, err)
+	assert.Greater(t, m.Len(), 0)
+
+	for _, p := range m.photons {
+		assert.InDelta(t, -1.0, p.Position.Y, 1e-3)
+		assert.Less(t, p.Direction.Y, 0.0)
+	}
+
+	w.Objects = w.Objects[:1]
+	m, err = BuildCausticMap(context.Background(), w, 20000, 0.2, rand.New(rand.NewSource(1)))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestCausticMapIsReproducible(t *testing.T) {
+	a, _ := BuildCausticMap(context.Background(), causticWorld(), 2000, 0.2, rand.New(rand.NewSource(9)))
+	b, _ := BuildCausticMap(context.Background(), causticWorld(), 2000, 0.2, rand.New(rand.NewSource(9)))
+
+	assert.Equal(t, a.photons, b.photons)
+}
+
+func TestRefractedPhotonsMatchRefractedColor(t *testing.T) {
+	w := causticWorld()
+	glass := w.Objects[1].(*Sphere)
+	glass.Material.Color = NewColor(1, 0, 0)
+
+	m, err := BuildCausticMap(context.Background(), w, 5000, 0.2, rand.New(rand.NewSource(1)))
+
+	assert.Nil(t, err)
+	assert.Greater(t, m.Len(), 0)
+
+	for _, p := range m.photons {
+		assert.InDelta(t, p.Power.R, p.Power.G, 1e-9)
+	}
+}
+
+func TestCausticMapStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	m, err := BuildCausticMap(ctx, causticWorld(), 20000, 0.2, rand.New(rand.NewSource(1)))
+
+	assert.Nil(t, m)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestCausticLightingFocusesUnderGlass(t *testing.T) {
+	w := causticWorld()
+	w.Caustics, _ = BuildCausticMap(context.Background(), w, 50000, 0.2, rand.New(rand.NewSource(1)))
+
+	shade := func(x float64) Color {
+		r := NewRay(NewPoint(x, 5, -5), Normalize(SubTuples(NewPoint(x, -1, 0), NewPoint(x, 5, -5))))
+		xs := NewIntersections(Intersect(w.Objects[0], r)...)
+		comps := PrepareComputations(xs[0], r, xs)
+
+		return CausticLighting(w, comps)
+	}
+
+	focus := maxComponent(shade(0))
+	edge := maxComponent(shade(3))
+
+	assert.Greater(t, focus, edge)
+	assert.InDelta(t, 1.0, focus, 0.5)
+
+	w.Caustics = nil
+	assert.True(t, ColorEquals(black, shade(0)))
+}
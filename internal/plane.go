@@ -1,10 +1,6 @@
 package internal
 
-import (
-	"math"
-	"math/rand"
-	"time"
-)
+import "math"
 
 type Plane struct {
 	ID               int64
@@ -12,17 +8,15 @@ type Plane struct {
 	Transform        Matrix
 	Inverse          Matrix
 	InverseTranspose Matrix
+	Motion           *Motion
 	Parent           Shape
+	media            mediumLink
 	HasShadow        bool
 }
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 func NewPlane() *Plane {
 	return &Plane{
-		ID:               rand.Int63(),
+		ID:               nextShapeID(),
 		Material:         NewDefaultMaterial(),
 		Transform:        NewIdentity4(),
 		Inverse:          NewIdentity4(),
@@ -36,6 +30,10 @@ func (p *Plane) GetID() int64 {
 	return p.ID
 }
 
+func (p *Plane) SetID(id int64) {
+	p.ID = id
+}
+
 func (p *Plane) LocalIntersect(localRay Ray) Intersections {
 	if math.Abs(localRay.Direction.Y) < float64EqualityThreshold {
 		return Intersections{}
@@ -67,12 +65,21 @@ func (p *Plane) GetInverseTranspose() Matrix {
 	return p.InverseTranspose
 }
 
+func (p *Plane) GetMotion() *Motion {
+	return p.Motion
+}
+
+func (p *Plane) SetMotion(motion *Motion) {
+	p.Motion = motion
+}
+
 func (p *Plane) GetMaterial() Material {
 	return p.Material
 }
 
 func (p *Plane) SetMaterial(material Material) {
 	p.Material = material
+	forgetMediumOwners(p)
 }
 
 func (p *Plane) GetParent() Shape {
@@ -81,6 +88,11 @@ func (p *Plane) GetParent() Shape {
 
 func (p *Plane) SetParent(s Shape) {
 	p.Parent = s
+	forgetMediumOwners(p)
+}
+
+func (p *Plane) mediumLink() *mediumLink {
+	return &p.media
 }
 
 func (p *Plane) CastsShadow() bool {
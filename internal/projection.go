@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+)
+
+type Projection int
+
+const (
+	ProjectionPerspective Projection = iota
+	ProjectionOrthographic
+	ProjectionEquirectangular
+	ProjectionCubemap
+	ProjectionFisheyeEquidistant
+	ProjectionFisheyeEquisolid
+)
+
+var projections = []Projection{
+	ProjectionPerspective,
+	ProjectionOrthographic,
+	ProjectionEquirectangular,
+	ProjectionCubemap,
+	ProjectionFisheyeEquidistant,
+	ProjectionFisheyeEquisolid,
+}
+
+func (p Projection) String() string {
+	return [...]string{"perspective", "orthographic", "equirectangular", "cubemap", "fisheye-equidistant", "fisheye-equisolid"}[p]
+}
+
+func (p Projection) IsPanoramic() bool {
+	return p >= ProjectionEquirectangular
+}
+
+func (p Projection) IsFisheye() bool {
+	return p == ProjectionFisheyeEquidistant || p == ProjectionFisheyeEquisolid
+}
+
+func ParseProjection(name string) (Projection, error) {
+	for _, p := range projections {
+		if p.String() == name {
+			return p, nil
+		}
+	}
+
+	return ProjectionPerspective, fmt.Errorf("unknown projection %q", name)
+}
+
+func Projections() []Projection {
+	return append([]Projection(nil), projections...)
+}
+
+func NewOrthographicCamera(hsize, vsize int, viewWidth float64) Camera {
+	c := Camera{Transform: NewIdentity4(), Projection: ProjectionOrthographic, ViewWidth: viewWidth}
+	c.SetSize(hsize, vsize, 0)
+
+	return c
+}
+
+func NewPanoramicCamera(hsize, vsize int, projection Projection, fov float64) Camera {
+	c := Camera{Transform: NewIdentity4(), Projection: projection}
+	c.SetSize(hsize, vsize, fov)
+
+	return c
+}
+
+func orthographicRay(worldX, worldY float64, inverse Matrix) Ray {
+	origin := MatrixTupleMultiply(inverse, NewPoint(worldX, worldY, 0))
+	direction := Normalize(MatrixTupleMultiply(inverse, NewVector(0, 0, -1)))
+
+	return NewRay(origin, direction)
+}
+
+var (
+	cameraForward = NewVector(0, 0, -1)
+	cameraRight   = NewVector(-1, 0, 0)
+	cameraUp      = NewVector(0, 1, 0)
+)
+
+func panoramicRay(c Camera, x, y float64) (Ray, bool) {
+	var direction Tuple
+	var ok bool
+
+	switch c.Projection {
+	case ProjectionEquirectangular:
+		direction, ok = equirectangularDirection(x/float64(c.Hsize), y/float64(c.Vsize)), true
+	case ProjectionCubemap:
+		direction, ok = cubemapDirection(x, y, c.Hsize, c.Vsize), true
+	default:
+		direction, ok = fisheyeDirection(c, x, y)
+	}
+
+	if !ok {
+		return Ray{}, false
+	}
+
+	inverse := MatrixInverse(c.Transform)
+	origin := MatrixTupleMultiply(inverse, NewPoint(0, 0, 0))
+
+	return NewRay(origin, Normalize(MatrixTupleMultiply(inverse, direction))), true
+}
+
+func cameraDirection(forward, right, up float64) Tuple {
+	return AddTuples(AddTuples(
+		TupleScalarMultiply(cameraForward, forward),
+		TupleScalarMultiply(cameraRight, right)),
+		TupleScalarMultiply(cameraUp, up))
+}
+
+func equirectangularDirection(u, v float64) Tuple {
+	longitude := (u - 0.5) * 2 * math.Pi
+	latitude := (0.5 - v) * math.Pi
+
+	return cameraDirection(
+		math.Cos(longitude)*math.Cos(latitude),
+		math.Sin(longitude)*math.Cos(latitude),
+		math.Sin(latitude),
+	)
+}
+
+func cubemapDirection(x, y float64, hsize, vsize int) Tuple {
+	faceWidth := float64(hsize) / 6
+	face := minInt(int(x/faceWidth), 5)
+	a := 2*(x-float64(face)*faceWidth)/faceWidth - 1
+	b := 1 - 2*y/float64(vsize)
+
+	switch face {
+	case 0:
+		return cameraDirection(-a, 1, b)
+	case 1:
+		return cameraDirection(a, -1, b)
+	case 2:
+		return cameraDirection(-b, a, 1)
+	case 3:
+		return cameraDirection(b, a, -1)
+	case 4:
+		return cameraDirection(1, a, b)
+	default:
+		return cameraDirection(-1, -a, b)
+	}
+}
+
+func fisheyeDirection(c Camera, x, y float64) (Tuple, bool) {
+	size := float64(minInt(c.Hsize, c.Vsize))
+	nx := (2*x - float64(c.Hsize)) / size
+	ny := (float64(c.Vsize) - 2*y) / size
+	r := math.Hypot(nx, ny)
+
+	if r > 1 || c.FOV <= 0 {
+		return Tuple{}, false
+	}
+
+	theta := r * c.FOV / 2
+
+	if c.Projection == ProjectionFisheyeEquisolid {
+		theta = 2 * math.Asin(math.Min(r*math.Sin(c.FOV/4), 1))
+	}
+
+	phi := math.Atan2(ny, nx)
+
+	return cameraDirection(math.Cos(theta), math.Sin(theta)*math.Cos(phi), math.Sin(theta)*math.Sin(phi)), true
+}
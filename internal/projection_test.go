@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProjection(t *testing.T) {
+	for _, p := range Projections() {
+		parsed, err := ParseProjection(p.String())
+
+		assert.Nil(t, err)
+		assert.Equal(t, p, parsed)
+	}
+
+	_, err := ParseProjection("isometric")
+	assert.NotNil(t, err)
+}
+
+func TestOrthographicCameraPixelSize(t *testing.T) {
+	c := NewOrthographicCamera(200, 100, 8)
+
+	assert.InDelta(t, 4.0, c.HalfWidth, float64EqualityThreshold)
+	assert.InDelta(t, 2.0, c.HalfHeight, float64EqualityThreshold)
+	assert.InDelta(t, 0.04, c.PixelSize, float64EqualityThreshold)
+}
+
+func TestOrthographicRaysAreParallel(t *testing.T) {
+	c := NewOrthographicCamera(201, 101, 4)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	center := RayForPixel(c, 100, 50)
+	assert.True(t, TupleEquals(NewPoint(0, 0, -5), center.Origin))
+	assert.True(t, TupleEquals(NewVector(0, 0, 1), center.Direction))
+
+	corner := RayForPixel(c, 0, 0)
+	assert.True(t, TupleEquals(NewVector(0, 0, 1), corner.Direction))
+	assert.InDelta(t, 2-c.PixelSize/2, math.Abs(corner.Origin.X), float64EqualityThreshold)
+	assert.InDelta(t, c.HalfHeight-c.PixelSize/2, corner.Origin.Y, float64EqualityThreshold)
+	assert.InDelta(t, -5.0, corner.Origin.Z, float64EqualityThreshold)
+}
+
+func TestRenderWithOrthographicCamera(t *testing.T) {
+	w := NewDefaultWorld()
+	c := NewOrthographicCamera(11, 11, 4)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	image := Render(c, w)
+
+	assert.True(t, ColorEquals(NewColor(0.38066, 0.47583, 0.2855), image.GetColorAtPixel(5, 5)))
+	assert.True(t, ColorEquals(NewColor(0, 0, 0), image.GetColorAtPixel(0, 0)))
+}
+
+func TestEquirectangularCameraCoversTheSphere(t *testing.T) {
+	c := NewPanoramicCamera(200, 100, ProjectionEquirectangular, 0)
+
+	testCases := []struct {
+		px, py    int
+		direction Tuple
+	}{
+		{100, 50, NewVector(0, 0, -1)},
+		{150, 50, NewVector(-1, 0, 0)},
+		{50, 50, NewVector(1, 0, 0)},
+		{0, 50, NewVector(0, 0, 1)},
+		{100, 0, NewVector(0, 1, 0)},
+	}
+
+	for _, test := range testCases {
+		r, ok := CameraRay(c, test.px, test.py, CameraSample{})
+
+		assert.True(t, ok)
+		assert.True(t, TupleEquals(NewPoint(0, 0, 0), r.Origin))
+		assert.True(t, TupleEquals(test.direction, r.Direction), "%d,%d", test.px, test.py)
+	}
+}
+
+func TestCubemapFaceCentres(t *testing.T) {
+	c := NewPanoramicCamera(600, 100, ProjectionCubemap, 0)
+	faces := []Tuple{
+		NewVector(-1, 0, 0),
+		NewVector(1, 0, 0),
+		NewVector(0, 1, 0),
+		NewVector(0, -1, 0),
+		NewVector(0, 0, -1),
+		NewVector(0, 0, 1),
+	}
+
+	for i, direction := range faces {
+		r, ok := CameraRay(c, 50+100*i, 50, CameraSample{})
+
+		assert.True(t, ok)
+		assert.True(t, TupleEquals(direction, r.Direction), "face %d", i)
+	}
+}
+
+func TestFisheyeCameraMapsRadiusToAngle(t *testing.T) {
+	for _, projection := range []Projection{ProjectionFisheyeEquidistant, ProjectionFisheyeEquisolid} {
+		c := NewPanoramicCamera(100, 100, projection, math.Pi)
+
+		center, ok := CameraRay(c, 50, 50, CameraSample{})
+		assert.True(t, ok)
+		assert.True(t, TupleEquals(NewVector(0, 0, -1), center.Direction), projection.String())
+
+		edge, ok := CameraRay(c, 99, 50, CameraSample{X: 1})
+		assert.True(t, ok)
+		assert.True(t, TupleEquals(NewVector(-1, 0, 0), edge.Direction), projection.String())
+
+		_, ok = CameraRay(c, 0, 0, CameraSample{})
+		assert.False(t, ok, projection.String())
+	}
+}
+
+func TestFisheyeCameraFollowsTransform(t *testing.T) {
+	c := NewPanoramicCamera(100, 100, ProjectionFisheyeEquidistant, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	r, ok := CameraRay(c, 50, 50, CameraSample{})
+
+	assert.True(t, ok)
+	assert.True(t, TupleEquals(NewPoint(0, 0, -5), r.Origin))
+	assert.True(t, TupleEquals(NewVector(0, 0, 1), r.Direction))
+}
+
+func TestRenderWithFisheyeCameraLeavesCornersEmpty(t *testing.T) {
+	w := NewDefaultWorld()
+	c := NewPanoramicCamera(11, 11, ProjectionFisheyeEquidistant, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	image := Render(c, w)
+
+	assert.True(t, ColorEquals(NewColor(0.38066, 0.47583, 0.2855), image.GetColorAtPixel(5, 5)))
+	assert.True(t, ColorEquals(NewColor(0, 0, 0), image.GetColorAtPixel(0, 0)))
+}
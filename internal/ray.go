@@ -1,8 +1,10 @@
 package internal
 
 type Ray struct {
-	Origin    Tuple
-	Direction Tuple
+	Origin     Tuple
+	Direction  Tuple
+	Wavelength float64
+	Time       float64
 }
 
 func NewRay(origin, direction Tuple) Ray {
@@ -12,6 +14,14 @@ func NewRay(origin, direction Tuple) Ray {
 	}
 }
 
+func NewSpectralRay(origin, direction Tuple, wavelength float64) Ray {
+	return Ray{
+		Origin:     origin,
+		Direction:  direction,
+		Wavelength: wavelength,
+	}
+}
+
 func Position(r Ray, dist float64) Tuple {
 	return AddTuples(r.Origin, r.Direction.MultiplyByScalar(dist))
 }
@@ -20,5 +30,10 @@ func TransformRay(r Ray, transform Matrix) Ray {
 	newOrigin := MatrixTupleMultiply(transform, r.Origin)
 	newDirection := MatrixTupleMultiply(transform, r.Direction)
 
-	return NewRay(newOrigin, newDirection)
+	return Ray{
+		Origin:     newOrigin,
+		Direction:  newDirection,
+		Wavelength: r.Wavelength,
+		Time:       r.Time,
+	}
 }
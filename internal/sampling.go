@@ -0,0 +1,179 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+type SamplePattern int
+
+const (
+	SampleStratified SamplePattern = iota
+	SampleJittered
+)
+
+func (p SamplePattern) String() string {
+	return [...]string{"stratified", "jittered"}[p]
+}
+
+func ParseSamplePattern(name string) (SamplePattern, error) {
+	for _, p := range []SamplePattern{SampleStratified, SampleJittered} {
+		if p.String() == name {
+			return p, nil
+		}
+	}
+
+	return SampleStratified, fmt.Errorf("unknown sample pattern %q", name)
+}
+
+type PixelFilter int
+
+const (
+	FilterBox PixelFilter = iota
+	FilterTent
+	FilterGaussian
+	FilterMitchell
+)
+
+func (f PixelFilter) String() string {
+	return [...]string{"box", "tent", "gaussian", "mitchell"}[f]
+}
+
+func ParsePixelFilter(name string) (PixelFilter, error) {
+	for _, f := range []PixelFilter{FilterBox, FilterTent, FilterGaussian, FilterMitchell} {
+		if f.String() == name {
+			return f, nil
+		}
+	}
+
+	return FilterBox, fmt.Errorf("unknown pixel filter %q", name)
+}
+
+func (f PixelFilter) DefaultRadius() float64 {
+	return [...]float64{0.5, 1.0, 1.5, 2.0}[f]
+}
+
+func (f PixelFilter) Weight(dx, dy, radius float64) float64 {
+	return f.weight1D(dx, radius) * f.weight1D(dy, radius)
+}
+
+func (f PixelFilter) weight1D(x, radius float64) float64 {
+	x = math.Abs(x)
+
+	if x > radius {
+		return 0
+	}
+
+	switch f {
+	case FilterTent:
+		return radius - x
+
+	case FilterGaussian:
+		alpha := 2.0
+		return math.Max(0, math.Exp(-alpha*x*x)-math.Exp(-alpha*radius*radius))
+
+	case FilterMitchell:
+		return mitchell1D(2 * x / radius)
+
+	default:
+		return 1
+	}
+}
+
+func mitchell1D(x float64) float64 {
+	b, c := 1.0/3.0, 1.0/3.0
+
+	if x > 1 {
+		return ((-b-6*c)*x*x*x + (6*b+30*c)*x*x + (-12*b-48*c)*x + (8*b + 24*c)) / 6
+	}
+
+	return ((12-9*b-6*c)*x*x*x + (-18+12*b+6*c)*x*x + (6 - 2*b)) / 6
+}
+
+type CameraSample struct {
+	X float64
+	Y float64
+
+	LensX float64
+	LensY float64
+
+	Time float64
+}
+
+var PixelCenter = CameraSample{X: 0.5, Y: 0.5}
+
+func SampleGridSize(samples int) int {
+	if samples <= 1 {
+		return 1
+	}
+
+	return int(math.Ceil(math.Sqrt(float64(samples))))
+}
+
+func PixelSamples(c Camera, rng *rand.Rand) []CameraSample {
+	n := SampleGridSize(c.Samples)
+
+	if n == 1 && c.Sampling != SampleJittered && !c.HasLens() && !c.HasShutter() {
+		return []CameraSample{PixelCenter}
+	}
+
+	radius := c.filterRadius()
+	samples := make([]CameraSample, 0, n*n)
+
+	var times []int
+
+	if c.HasShutter() {
+		times = rng.Perm(n * n)
+	}
+
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			u, v := 0.5, 0.5
+
+			if c.Sampling == SampleJittered {
+				u, v = rng.Float64(), rng.Float64()
+			}
+
+			s := CameraSample{
+				X: 0.5 + (2*(float64(i)+u)/float64(n)-1)*radius,
+				Y: 0.5 + (2*(float64(j)+v)/float64(n)-1)*radius,
+			}
+
+			if c.HasLens() {
+				s.LensX, s.LensY = c.sampleLens(rng)
+			}
+
+			if times != nil {
+				s.Time = (float64(times[len(samples)]) + rng.Float64()) / float64(n*n)
+			}
+
+			samples = append(samples, s)
+		}
+	}
+
+	return samples
+}
+
+func FilterSamples(c Camera, samples []CameraSample, colors []Color) Color {
+	if len(samples) == 1 {
+		return colors[0]
+	}
+
+	radius := c.filterRadius()
+	var sum, plain Color
+	var totalWeight float64
+
+	for i, s := range samples {
+		weight := c.Filter.Weight(s.X-0.5, s.Y-0.5, radius)
+		sum = AddColors(sum, ColorScalarMultiply(colors[i], weight))
+		plain = AddColors(plain, colors[i])
+		totalWeight += weight
+	}
+
+	if totalWeight <= 0 {
+		return ColorScalarDivide(plain, float64(len(samples)))
+	}
+
+	return ColorScalarDivide(sum, totalWeight)
+}
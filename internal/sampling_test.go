@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterWeights(t *testing.T) {
+	testCases := []struct {
+		filter PixelFilter
+		x, y   float64
+		result float64
+	}{
+		{FilterBox, 0, 0, 1},
+		{FilterBox, 0.4, -0.4, 1},
+		{FilterBox, 0.6, 0, 0},
+		{FilterTent, 0, 0, 1},
+		{FilterTent, 0.5, 0, 0.5},
+		{FilterTent, 1.2, 0, 0},
+		{FilterGaussian, 1.5, 0, 0},
+		{FilterMitchell, 0, 0, 8.0 / 9.0 * 8.0 / 9.0},
+		{FilterMitchell, 2, 0, 0},
+	}
+
+	for _, test := range testCases {
+		weight := test.filter.Weight(test.x, test.y, test.filter.DefaultRadius())
+		assert.InDelta(t, test.result, weight, float64EqualityThreshold, test.filter.String())
+	}
+}
+
+func TestFilterWeightsDecreaseAwayFromCenter(t *testing.T) {
+	for _, filter := range []PixelFilter{FilterTent, FilterGaussian, FilterMitchell} {
+		r := filter.DefaultRadius()
+		assert.True(t, filter.Weight(0, 0, r) > filter.Weight(r/4, 0, r), filter.String())
+		assert.True(t, filter.Weight(r/4, 0, r) > filter.Weight(r/2, r/2, r), filter.String())
+	}
+}
+
+func TestSampleGridSize(t *testing.T) {
+	testCases := []struct {
+		samples, result int
+	}{
+		{0, 1},
+		{1, 1},
+		{4, 2},
+		{5, 3},
+		{16, 4},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.result, SampleGridSize(test.samples))
+	}
+}
+
+func TestSingleSampleIsPixelCenter(t *testing.T) {
+	c := NewCamera(10, 10, math.Pi/2)
+	samples := PixelSamples(c, rand.New(rand.NewSource(1)))
+
+	assert.Equal(t, []CameraSample{PixelCenter}, samples)
+}
+
+func TestStratifiedSamplesCoverFilterFootprint(t *testing.T) {
+	c := NewCamera(10, 10, math.Pi/2)
+	c.Samples = 4
+	c.Filter = FilterTent
+
+	samples := PixelSamples(c, rand.New(rand.NewSource(1)))
+
+	assert.Equal(t, []CameraSample{
+		{X: 0, Y: 0},
+		{X: 1, Y: 0},
+		{X: 0, Y: 1},
+		{X: 1, Y: 1},
+	}, samples)
+}
+
+func TestJitteredSamplesStayInsideTheirStrata(t *testing.T) {
+	c := NewCamera(10, 10, math.Pi/2)
+	c.Samples = 9
+	c.Sampling = SampleJittered
+
+	samples := PixelSamples(c, rand.New(rand.NewSource(7)))
+
+	assert.Equal(t, 9, len(samples))
+
+	for i, s := range samples {
+		col, row := float64(i%3), float64(i/3)
+		assert.True(t, s.X >= col/3 && s.X <= (col+1)/3)
+		assert.True(t, s.Y >= row/3 && s.Y <= (row+1)/3)
+	}
+}
+
+func TestFilterSamplesAveragesBoxSamples(t *testing.T) {
+	c := NewCamera(10, 10, math.Pi/2)
+	samples := []CameraSample{{X: 0.25, Y: 0.25}, {X: 0.75, Y: 0.75}}
+	colors := []Color{NewColor(1, 0, 0), NewColor(0, 0, 1)}
+
+	assert.True(t, ColorEquals(NewColor(0.5, 0, 0.5), FilterSamples(c, samples, colors)))
+}
+
+func TestRayForSampleAtPixelCenterMatchesRayForPixel(t *testing.T) {
+	c := NewCamera(201, 101, math.Pi/2)
+	c.Transform = MatrixMultiply(RotateY(math.Pi/4), Translate(0, -2, 5))
+
+	assert.Equal(t, RayForPixel(c, 30, 40), RayForSample(c, 30, 40, PixelCenter))
+}
+
+func TestSupersamplingSmoothsEdges(t *testing.T) {
+	w := NewWorld()
+	w.Lights = append(w.Lights, NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1)))
+	s := NewSphere()
+	s.Material.Ambient = 1
+	s.Material.Diffuse = 0
+	s.Material.Specular = 0
+	w.Objects = append(w.Objects, s)
+
+	c := NewCamera(21, 21, math.Pi/3)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+	c.Samples = 16
+
+	image := Render(c, w)
+	partial := 0
+
+	for _, pixel := range image.Pixels {
+		if pixel.R > 0.05 && pixel.R < 0.95 {
+			partial++
+		}
+	}
+
+	assert.True(t, partial > 0)
+	assert.InDelta(t, 1.0, image.GetColorAtPixel(10, 10).R, float64EqualityThreshold)
+	assert.InDelta(t, 0.0, image.GetColorAtPixel(0, 0).R, float64EqualityThreshold)
+}
+
+func TestParseSamplingNames(t *testing.T) {
+	filter, err := ParsePixelFilter("mitchell")
+	assert.Nil(t, err)
+	assert.Equal(t, FilterMitchell, filter)
+
+	pattern, err := ParseSamplePattern("jittered")
+	assert.Nil(t, err)
+	assert.Equal(t, SampleJittered, pattern)
+
+	_, err = ParsePixelFilter("lanczos")
+	assert.NotNil(t, err)
+}
@@ -1,14 +1,22 @@
 package internal
 
-import "github.com/google/go-cmp/cmp"
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/go-cmp/cmp"
+)
 
 type Shape interface {
 	GetID() int64
+	SetID(id int64)
 
 	GetTransform() Matrix
 	SetTransform(t Matrix)
 	GetInverse() Matrix
 	GetInverseTranspose() Matrix
+	GetMotion() *Motion
+	SetMotion(m *Motion)
 
 	GetMaterial() Material
 	SetMaterial(m Material)
@@ -20,6 +28,14 @@ type Shape interface {
 	LocalNormalAt(point Tuple, i Intersection) Tuple
 
 	CastsShadow() bool
+
+	mediumLink() *mediumLink
+}
+
+var lastShapeID int64
+
+func nextShapeID() int64 {
+	return atomic.AddInt64(&lastShapeID, 1)
 }
 
 type TestShape struct {
@@ -27,9 +43,12 @@ type TestShape struct {
 	Transform        Matrix
 	Inverse          Matrix
 	InverseTranspose Matrix
+	Motion           *Motion
 	Parent           Shape
+	media            mediumLink
 	SavedRay         Ray
 	HasShadow        bool
+	mu               sync.Mutex
 }
 
 func NewTestShape() *TestShape {
@@ -48,6 +67,8 @@ func (t *TestShape) GetID() int64 {
 	return 0
 }
 
+func (t *TestShape) SetID(id int64) {}
+
 func (t *TestShape) GetTransform() Matrix {
 	return t.Transform
 }
@@ -66,16 +87,28 @@ func (t *TestShape) GetInverseTranspose() Matrix {
 	return t.InverseTranspose
 }
 
+func (t *TestShape) GetMotion() *Motion {
+	return t.Motion
+}
+
+func (t *TestShape) SetMotion(motion *Motion) {
+	t.Motion = motion
+}
+
 func (t *TestShape) GetMaterial() Material {
 	return t.Material
 }
 
 func (t *TestShape) SetMaterial(material Material) {
 	t.Material = material
+	forgetMediumOwners(t)
 }
 
 func (t *TestShape) LocalIntersect(localRay Ray) Intersections {
+	t.mu.Lock()
 	t.SavedRay = localRay
+	t.mu.Unlock()
+
 	return Intersections{}
 }
 
@@ -89,6 +122,11 @@ func (t *TestShape) GetParent() Shape {
 
 func (t *TestShape) SetParent(s Shape) {
 	t.Parent = s
+	forgetMediumOwners(t)
+}
+
+func (t *TestShape) mediumLink() *mediumLink {
+	return &t.media
 }
 
 func (t *TestShape) CastsShadow() bool {
@@ -96,7 +134,7 @@ func (t *TestShape) CastsShadow() bool {
 }
 
 func Intersect(s Shape, ray Ray) Intersections {
-	r := TransformRay(ray, s.GetInverse())
+	r := TransformRay(ray, InverseAt(s, ray.Time))
 	return s.LocalIntersect(r)
 }
 
@@ -113,20 +151,28 @@ func ShapeHasParent(s Shape) bool {
 }
 
 func WorldToObject(s Shape, point Tuple) Tuple {
+	return WorldToObjectAtTime(s, point, 0)
+}
+
+func WorldToObjectAtTime(s Shape, point Tuple, time float64) Tuple {
 	if ShapeHasParent(s) {
-		point = WorldToObject(s.GetParent(), point)
+		point = WorldToObjectAtTime(s.GetParent(), point, time)
 	}
 
-	return MatrixTupleMultiply(s.GetInverse(), point)
+	return MatrixTupleMultiply(InverseAt(s, time), point)
 }
 
 func NormalToWorld(s Shape, normal Tuple) Tuple {
-	normal = MatrixTupleMultiply(s.GetInverseTranspose(), normal)
+	return NormalToWorldAtTime(s, normal, 0)
+}
+
+func NormalToWorldAtTime(s Shape, normal Tuple, time float64) Tuple {
+	normal = MatrixTupleMultiply(InverseTransposeAt(s, time), normal)
 	normal.W = 0
 	normal = Normalize(normal)
 
 	if ShapeHasParent(s) {
-		normal = NormalToWorld(s.GetParent(), normal)
+		normal = NormalToWorldAtTime(s.GetParent(), normal, time)
 	}
 
 	return normal
@@ -89,3 +89,12 @@ func TestShapeHasParentAttribute(t *testing.T) {
 
 	assert.Nil(t, s.Parent)
 }
+
+func TestShapeIDsAreUniqueAndIncreasing(t *testing.T) {
+	s := NewSphere()
+	g := NewGroup()
+	c := NewCube()
+
+	assert.True(t, g.GetID() > s.GetID())
+	assert.True(t, c.GetID() > g.GetID())
+}
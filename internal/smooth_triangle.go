@@ -1,10 +1,6 @@
 package internal
 
-import (
-	"math"
-	"math/rand"
-	"time"
-)
+import "math"
 
 type SmoothTriangle struct {
 	ID               int64
@@ -12,7 +8,9 @@ type SmoothTriangle struct {
 	Transform        Matrix
 	Inverse          Matrix
 	InverseTranspose Matrix
+	Motion           *Motion
 	Parent           Shape
+	media            mediumLink
 	P1, P2, P3       Tuple
 	N1, N2, N3       Tuple
 	E1, E2           Tuple
@@ -20,15 +18,11 @@ type SmoothTriangle struct {
 	HasShadow        bool
 }
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 func NewSmoothTriangle(p1, p2, p3, n1, n2, n3 Tuple) *SmoothTriangle {
 	e1, e2 := SubTuples(p2, p1), SubTuples(p3, p1)
 
 	return &SmoothTriangle{
-		ID:               rand.Int63(),
+		ID:               nextShapeID(),
 		Material:         NewDefaultMaterial(),
 		Transform:        NewIdentity4(),
 		Inverse:          NewIdentity4(),
@@ -51,6 +45,10 @@ func (tri *SmoothTriangle) GetID() int64 {
 	return tri.ID
 }
 
+func (tri *SmoothTriangle) SetID(id int64) {
+	tri.ID = id
+}
+
 func (tri *SmoothTriangle) LocalIntersect(localRay Ray) Intersections {
 	dirCrossE2 := Cross(localRay.Direction, tri.E2)
 	det := Dot(tri.E1, dirCrossE2)
@@ -107,12 +105,21 @@ func (tri *SmoothTriangle) GetInverseTranspose() Matrix {
 	return tri.InverseTranspose
 }
 
+func (tri *SmoothTriangle) GetMotion() *Motion {
+	return tri.Motion
+}
+
+func (tri *SmoothTriangle) SetMotion(motion *Motion) {
+	tri.Motion = motion
+}
+
 func (tri *SmoothTriangle) GetMaterial() Material {
 	return tri.Material
 }
 
 func (tri *SmoothTriangle) SetMaterial(material Material) {
 	tri.Material = material
+	forgetMediumOwners(tri)
 }
 
 func (tri *SmoothTriangle) GetParent() Shape {
@@ -121,6 +128,11 @@ func (tri *SmoothTriangle) GetParent() Shape {
 
 func (tri *SmoothTriangle) SetParent(s Shape) {
 	tri.Parent = s
+	forgetMediumOwners(tri)
+}
+
+func (tri *SmoothTriangle) mediumLink() *mediumLink {
+	return &tri.media
 }
 
 func (tri *SmoothTriangle) CastsShadow() bool {
@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+const (
+	MinWavelength = 380.0
+	MaxWavelength = 780.0
+)
+
+type DispersionModel int
+
+const (
+	DispersionCauchy DispersionModel = iota
+	DispersionSellmeier
+)
+
+func (m DispersionModel) String() string {
+	return [...]string{"cauchy", "sellmeier"}[m]
+}
+
+func ParseDispersionModel(name string) (DispersionModel, error) {
+	for _, m := range []DispersionModel{DispersionCauchy, DispersionSellmeier} {
+		if m.String() == name {
+			return m, nil
+		}
+	}
+
+	return DispersionCauchy, fmt.Errorf("unknown dispersion model %q", name)
+}
+
+type Dispersion struct {
+	Model        DispersionModel
+	Coefficients []float64
+}
+
+func NewCauchyDispersion(coefficients ...float64) *Dispersion {
+	return &Dispersion{Model: DispersionCauchy, Coefficients: coefficients}
+}
+
+func NewSellmeierDispersion(b, c []float64) (*Dispersion, error) {
+	if len(b) != len(c) || len(b) == 0 {
+		return nil, fmt.Errorf("sellmeier dispersion needs matching, non-empty B and C coefficients")
+	}
+
+	return &Dispersion{Model: DispersionSellmeier, Coefficients: append(append([]float64(nil), b...), c...)}, nil
+}
+
+func (d *Dispersion) IndexAt(wavelength float64) float64 {
+	micrometres := wavelength / 1000
+	l2 := micrometres * micrometres
+
+	switch d.Model {
+	case DispersionSellmeier:
+		terms := len(d.Coefficients) / 2
+		n2 := 1.0
+
+		for i := 0; i < terms; i++ {
+			n2 += d.Coefficients[i] * l2 / (l2 - d.Coefficients[terms+i])
+		}
+
+		return math.Sqrt(math.Max(n2, 0))
+
+	default:
+		n := 0.0
+
+		for i, c := range d.Coefficients {
+			n += c / math.Pow(l2, float64(i))
+		}
+
+		return n
+	}
+}
+
+func (m Material) IndexAt(wavelength float64) float64 {
+	if m.Dispersion == nil || wavelength <= 0 {
+		return m.RefractiveIndex
+	}
+
+	return m.Dispersion.IndexAt(wavelength)
+}
+
+func ColorMatching(wavelength float64) (float64, float64, float64) {
+	x := 1.056*piecewiseGaussian(wavelength, 599.8, 37.9, 31.0) +
+		0.362*piecewiseGaussian(wavelength, 442.0, 16.0, 26.7) -
+		0.065*piecewiseGaussian(wavelength, 501.1, 20.4, 26.2)
+	y := 0.821*piecewiseGaussian(wavelength, 568.8, 46.9, 40.5) +
+		0.286*piecewiseGaussian(wavelength, 530.9, 16.3, 31.1)
+	z := 1.217*piecewiseGaussian(wavelength, 437.0, 11.8, 36.0) +
+		0.681*piecewiseGaussian(wavelength, 459.0, 26.0, 13.8)
+
+	return x, y, z
+}
+
+func piecewiseGaussian(x, mu, sigmaLow, sigmaHigh float64) float64 {
+	sigma := sigmaHigh
+
+	if x < mu {
+		sigma = sigmaLow
+	}
+
+	t := (x - mu) / sigma
+
+	return math.Exp(-0.5 * t * t)
+}
+
+func XYZToRGB(x, y, z float64) Color {
+	return NewColor(
+		3.2406*x-1.5372*y-0.4986*z,
+		-0.9689*x+1.8758*y+0.0415*z,
+		0.0557*x-0.2040*y+1.0570*z,
+	)
+}
+
+var wavelengthNormalization = func() Color {
+	var total Color
+
+	for l := MinWavelength; l < MaxWavelength; l++ {
+		total = AddColors(total, XYZToRGB(ColorMatching(l+0.5)))
+	}
+
+	return ColorScalarDivide(total, MaxWavelength-MinWavelength)
+}()
+
+func WavelengthWeight(wavelength float64) Color {
+	c := XYZToRGB(ColorMatching(wavelength))
+
+	return NewColor(c.R/wavelengthNormalization.R, c.G/wavelengthNormalization.G, c.B/wavelengthNormalization.B)
+}
+
+func spectralRadiance(w World, r Ray, integrator Integrator, count int, rng *rand.Rand) Color {
+	count = maxInt(count, 1)
+	offset := rng.Float64()
+	span := MaxWavelength - MinWavelength
+
+	var total Color
+
+	for i := 0; i < count; i++ {
+		wavelength := MinWavelength + span*(float64(i)+offset)/float64(count)
+		ray := r
+		ray.Wavelength = wavelength
+		total = AddColors(total, HadamardProduct(integrator.Li(w, ray, rng), WavelengthWeight(wavelength)))
+	}
+
+	return ColorScalarDivide(total, float64(count))
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCauchyDispersion(t *testing.T) {
+	d := NewCauchyDispersion(1.5, 0.005)
+
+	assert.InDelta(t, 1.5+0.005/0.25, d.IndexAt(500), 1e-9)
+	assert.Greater(t, d.IndexAt(400), d.IndexAt(700))
+}
+
+func TestSellmeierDispersionMatchesBK7(t *testing.T) {
+	d, err := NewSellmeierDispersion(
+		[]float64{1.03961212, 0.231792344, 1.01046945},
+		[]float64{0.00600069867, 0.0200179144, 103.560653},
+	)
+
+	assert.Nil(t, err)
+	assert.InDelta(t, 1.5168, d.IndexAt(587.6), 1e-4)
+
+	_, err = NewSellmeierDispersion([]float64{1}, nil)
+	assert.NotNil(t, err)
+}
+
+func TestMaterialIndexFallsBackToRefractiveIndex(t *testing.T) {
+	m := NewDefaultMaterial()
+	m.RefractiveIndex = 1.33
+
+	assert.Equal(t, 1.33, m.IndexAt(0))
+	assert.Equal(t, 1.33, m.IndexAt(550))
+
+	m.Dispersion = NewCauchyDispersion(1.5, 0.01)
+	assert.Equal(t, 1.33, m.IndexAt(0))
+	assert.InDelta(t, 1.5+0.01/0.3025, m.IndexAt(550), 1e-9)
+}
+
+func TestParseDispersionModel(t *testing.T) {
+	m, err := ParseDispersionModel("sellmeier")
+	assert.Nil(t, err)
+	assert.Equal(t, DispersionSellmeier, m)
+
+	_, err = ParseDispersionModel("abbe")
+	assert.NotNil(t, err)
+}
+
+func TestWavelengthWeightsAverageToWhite(t *testing.T) {
+	var total Color
+
+	for l := MinWavelength; l < MaxWavelength; l++ {
+		total = AddColors(total, WavelengthWeight(l+0.5))
+	}
+
+	assert.True(t, ColorEquals(NewColor(1, 1, 1), ColorScalarDivide(total, MaxWavelength-MinWavelength)))
+
+	blue, red := WavelengthWeight(450), WavelengthWeight(650)
+	assert.Greater(t, blue.B, blue.R)
+	assert.Greater(t, red.R, red.B)
+}
+
+func TestDispersionChangesRefractiveIndicesByWavelength(t *testing.T) {
+	shape := NewGlassSphere()
+	shape.Material.Dispersion = NewCauchyDispersion(1.5, 0.01)
+
+	blue := NewSpectralRay(NewPoint(0, 0, -5), NewVector(0, 0, 1), 420)
+	red := NewSpectralRay(NewPoint(0, 0, -5), NewVector(0, 0, 1), 680)
+	xs := NewIntersections(NewIntersection(4, shape), NewIntersection(6, shape))
+
+	blueComps := PrepareComputations(xs[0], blue, xs)
+	redComps := PrepareComputations(xs[0], red, xs)
+
+	assert.Equal(t, 420.0, blueComps.Wavelength)
+	assert.Greater(t, blueComps.N2, redComps.N2)
+	assert.Equal(t, 1.5, PrepareComputations(xs[0], NewRay(blue.Origin, blue.Direction), xs).N2)
+}
+
+func TestSpectralRenderMatchesRGBWithoutDispersion(t *testing.T) {
+	w := NewDefaultWorld()
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+	integrator := WhittedIntegrator{Depth: RecursionDepth}
+
+	rgb := integrator.Li(w, r, nil)
+	spectral := spectralRadiance(w, r, integrator, 400, rand.New(rand.NewSource(1)))
+
+	assert.InDelta(t, rgb.R, spectral.R, 0.01)
+	assert.InDelta(t, rgb.G, spectral.G, 0.01)
+	assert.InDelta(t, rgb.B, spectral.B, 0.01)
+}
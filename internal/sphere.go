@@ -1,10 +1,6 @@
 package internal
 
-import (
-	"math"
-	"math/rand"
-	"time"
-)
+import "math"
 
 type Sphere struct {
 	ID               int64
@@ -12,17 +8,15 @@ type Sphere struct {
 	Transform        Matrix
 	Inverse          Matrix
 	InverseTranspose Matrix
+	Motion           *Motion
 	Parent           Shape
+	media            mediumLink
 	HasShadow        bool
 }
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 func NewSphere() *Sphere {
 	return &Sphere{
-		ID:               rand.Int63(),
+		ID:               nextShapeID(),
 		Material:         NewDefaultMaterial(),
 		Transform:        NewIdentity4(),
 		Inverse:          NewIdentity4(),
@@ -44,6 +38,10 @@ func (s *Sphere) GetID() int64 {
 	return s.ID
 }
 
+func (s *Sphere) SetID(id int64) {
+	s.ID = id
+}
+
 func (s *Sphere) LocalIntersect(localRay Ray) Intersections {
 	sphereToRay := SubTuples(localRay.Origin, NewPoint(0, 0, 0))
 	a := Dot(localRay.Direction, localRay.Direction)
@@ -83,12 +81,21 @@ func (s *Sphere) GetInverseTranspose() Matrix {
 	return s.InverseTranspose
 }
 
+func (s *Sphere) GetMotion() *Motion {
+	return s.Motion
+}
+
+func (s *Sphere) SetMotion(motion *Motion) {
+	s.Motion = motion
+}
+
 func (s *Sphere) GetMaterial() Material {
 	return s.Material
 }
 
 func (s *Sphere) SetMaterial(material Material) {
 	s.Material = material
+	forgetMediumOwners(s)
 }
 
 func (s *Sphere) GetParent() Shape {
@@ -97,6 +104,11 @@ func (s *Sphere) GetParent() Shape {
 
 func (s *Sphere) SetParent(shape Shape) {
 	s.Parent = shape
+	forgetMediumOwners(s)
+}
+
+func (s *Sphere) mediumLink() *mediumLink {
+	return &s.media
 }
 
 func (s *Sphere) CastsShadow() bool {
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+)
+
+type Eye int
+
+const (
+	EyeLeft Eye = iota
+	EyeRight
+)
+
+func (e Eye) String() string {
+	return [...]string{"left", "right"}[e]
+}
+
+type StereoRig struct {
+	Camera      Camera
+	Interocular float64
+	Convergence float64
+}
+
+func NewStereoRig(c Camera, interocular, convergence float64) StereoRig {
+	return StereoRig{Camera: c, Interocular: interocular, Convergence: convergence}
+}
+
+func (s StereoRig) Eye(eye Eye) Camera {
+	offset := s.Interocular / 2
+
+	if eye == EyeRight {
+		offset = -offset
+	}
+
+	c := s.Camera
+	c.Transform = MatrixMultiply(Translate(-offset, 0, 0), s.Camera.Transform)
+
+	if s.Convergence > 0 {
+		c.ShiftX = s.Camera.ShiftX - offset/s.Convergence
+	}
+
+	return c
+}
+
+func (s StereoRig) Eyes() (Camera, Camera) {
+	return s.Eye(EyeLeft), s.Eye(EyeRight)
+}
+
+type StereoLayout int
+
+const (
+	StereoSeparate StereoLayout = iota
+	StereoSideBySide
+	StereoTopBottom
+	StereoAnaglyph
+)
+
+var stereoLayouts = []StereoLayout{StereoSeparate, StereoSideBySide, StereoTopBottom, StereoAnaglyph}
+
+func (l StereoLayout) String() string {
+	return [...]string{"separate", "side-by-side", "top-bottom", "anaglyph"}[l]
+}
+
+func ParseStereoLayout(name string) (StereoLayout, error) {
+	for _, l := range stereoLayouts {
+		if l.String() == name {
+			return l, nil
+		}
+	}
+
+	return StereoSeparate, fmt.Errorf("unknown stereo layout %q", name)
+}
+
+func StereoLayouts() []StereoLayout {
+	return append([]StereoLayout(nil), stereoLayouts...)
+}
+
+func CombineStereo(left, right *Canvas, layout StereoLayout) (*Canvas, error) {
+	if left.W != right.W || left.H != right.H {
+		return nil, fmt.Errorf("stereo images differ in size: %dx%d and %dx%d", left.W, left.H, right.W, right.H)
+	}
+
+	switch layout {
+	case StereoSideBySide:
+		combined := NewCanvas(2*left.W, left.H)
+		pasteCanvas(combined, left, 0, 0)
+		pasteCanvas(combined, right, left.W, 0)
+
+		return combined, nil
+
+	case StereoTopBottom:
+		combined := NewCanvas(left.W, 2*left.H)
+		pasteCanvas(combined, left, 0, 0)
+		pasteCanvas(combined, right, 0, left.H)
+
+		return combined, nil
+
+	case StereoAnaglyph:
+		return Anaglyph(left, right), nil
+
+	default:
+		return nil, fmt.Errorf("stereo layout %q keeps the eyes in separate images", layout)
+	}
+}
+
+func Anaglyph(left, right *Canvas) *Canvas {
+	combined := NewCanvas(left.W, left.H)
+
+	if left.Alpha != nil || right.Alpha != nil {
+		combined.EnableAlpha()
+	}
+
+	for y := 0; y < left.H; y++ {
+		for x := 0; x < left.W; x++ {
+			l, r := left.GetColorAtPixel(x, y), right.GetColorAtPixel(x, y)
+
+			combined.WritePixelAtCoord(x, y, NewColor(l.R, r.G, r.B))
+			combined.WriteAlphaAtCoord(x, y, math.Max(left.GetAlphaAtPixel(x, y), right.GetAlphaAtPixel(x, y)))
+		}
+	}
+
+	return combined
+}
+
+func pasteCanvas(dst, src *Canvas, x0, y0 int) {
+	if src.Alpha != nil {
+		dst.EnableAlpha()
+	}
+
+	for y := 0; y < src.H; y++ {
+		for x := 0; x < src.W; x++ {
+			dst.WritePixelAtCoord(x0+x, y0+y, src.GetColorAtPixel(x, y))
+			dst.WriteAlphaAtCoord(x0+x, y0+y, src.GetAlphaAtPixel(x, y))
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStereoLayout(t *testing.T) {
+	for _, l := range StereoLayouts() {
+		parsed, err := ParseStereoLayout(l.String())
+
+		assert.Nil(t, err)
+		assert.Equal(t, l, parsed)
+	}
+
+	_, err := ParseStereoLayout("interlaced")
+	assert.NotNil(t, err)
+}
+
+func TestStereoEyesAreSeparatedByInterocularDistance(t *testing.T) {
+	c := NewCamera(101, 101, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+	left, right := NewStereoRig(c, 0.2, 5).Eyes()
+
+	l := RayForPixel(left, 50, 50)
+	r := RayForPixel(right, 50, 50)
+
+	assert.True(t, TupleEquals(NewPoint(-0.1, 0, -5), l.Origin))
+	assert.True(t, TupleEquals(NewPoint(0.1, 0, -5), r.Origin))
+}
+
+func TestStereoEyesConvergeOnTheCentre(t *testing.T) {
+	c := NewCamera(101, 101, math.Pi/2)
+	c.Transform = ViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	for _, eye := range []Eye{EyeLeft, EyeRight} {
+		camera := NewStereoRig(c, 0.5, 5).Eye(eye)
+		r := RayForPixel(camera, 50, 50)
+		point := Position(r, 5/r.Direction.Z)
+
+		assert.True(t, TupleEquals(NewPoint(0, 0, 0), point), eye.String())
+	}
+}
+
+func TestStereoEyesUseOffAxisShift(t *testing.T) {
+	c := NewCamera(100, 50, math.Pi/3)
+	left, right := NewStereoRig(c, 0.1, 2).Eyes()
+
+	assert.InDelta(t, -0.025, left.ShiftX, float64EqualityThreshold)
+	assert.InDelta(t, 0.025, right.ShiftX, float64EqualityThreshold)
+	assert.InDelta(t, 0.0, c.ShiftX, float64EqualityThreshold)
+}
+
+func TestCombineStereoLayouts(t *testing.T) {
+	red, blue := NewColor(1, 0, 0), NewColor(0, 0, 1)
+	left, right := NewCanvas(2, 1), NewCanvas(2, 1)
+
+	for x := 0; x < 2; x++ {
+		left.WritePixelAtCoord(x, 0, red)
+		right.WritePixelAtCoord(x, 0, blue)
+	}
+
+	side, err := CombineStereo(left, right, StereoSideBySide)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, side.W)
+	assert.Equal(t, 1, side.H)
+	assert.Equal(t, red, side.GetColorAtPixel(1, 0))
+	assert.Equal(t, blue, side.GetColorAtPixel(2, 0))
+
+	stacked, err := CombineStereo(left, right, StereoTopBottom)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, stacked.W)
+	assert.Equal(t, 2, stacked.H)
+	assert.Equal(t, red, stacked.GetColorAtPixel(0, 0))
+	assert.Equal(t, blue, stacked.GetColorAtPixel(0, 1))
+
+	_, err = CombineStereo(left, right, StereoSeparate)
+	assert.NotNil(t, err)
+
+	_, err = CombineStereo(left, NewCanvas(3, 1), StereoSideBySide)
+	assert.NotNil(t, err)
+}
+
+func TestAnaglyphTakesRedFromLeftAndCyanFromRight(t *testing.T) {
+	left, right := NewCanvas(1, 1), NewCanvas(1, 1)
+	left.WritePixelAtCoord(0, 0, NewColor(0.8, 0.1, 0.2))
+	right.WritePixelAtCoord(0, 0, NewColor(0.3, 0.6, 0.7))
+	right.EnableAlpha()
+	right.WriteAlphaAtCoord(0, 0, 0)
+
+	combined := Anaglyph(left, right)
+
+	assert.Equal(t, NewColor(0.8, 0.6, 0.7), combined.GetColorAtPixel(0, 0))
+	assert.InDelta(t, 1.0, combined.GetAlphaAtPixel(0, 0), float64EqualityThreshold)
+}
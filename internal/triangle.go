@@ -1,10 +1,6 @@
 package internal
 
-import (
-	"math"
-	"math/rand"
-	"time"
-)
+import "math"
 
 type Triangle struct {
 	ID               int64
@@ -12,22 +8,20 @@ type Triangle struct {
 	Transform        Matrix
 	Inverse          Matrix
 	InverseTranspose Matrix
+	Motion           *Motion
 	Parent           Shape
+	media            mediumLink
 	P1, P2, P3       Tuple
 	E1, E2           Tuple
 	Normal           Tuple
 	HasShadow        bool
 }
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 func NewTriangle(p1, p2, p3 Tuple) *Triangle {
 	e1, e2 := SubTuples(p2, p1), SubTuples(p3, p1)
 
 	return &Triangle{
-		ID:               rand.Int63(),
+		ID:               nextShapeID(),
 		Material:         NewDefaultMaterial(),
 		Transform:        NewIdentity4(),
 		Inverse:          NewIdentity4(),
@@ -47,6 +41,10 @@ func (tri *Triangle) GetID() int64 {
 	return tri.ID
 }
 
+func (tri *Triangle) SetID(id int64) {
+	tri.ID = id
+}
+
 func (tri *Triangle) LocalIntersect(localRay Ray) Intersections {
 	dirCrossE2 := Cross(localRay.Direction, tri.E2)
 	det := Dot(tri.E1, dirCrossE2)
@@ -97,12 +95,21 @@ func (tri *Triangle) GetInverseTranspose() Matrix {
 	return tri.InverseTranspose
 }
 
+func (tri *Triangle) GetMotion() *Motion {
+	return tri.Motion
+}
+
+func (tri *Triangle) SetMotion(motion *Motion) {
+	tri.Motion = motion
+}
+
 func (tri *Triangle) GetMaterial() Material {
 	return tri.Material
 }
 
 func (tri *Triangle) SetMaterial(material Material) {
 	tri.Material = material
+	forgetMediumOwners(tri)
 }
 
 func (tri *Triangle) GetParent() Shape {
@@ -111,6 +118,11 @@ func (tri *Triangle) GetParent() Shape {
 
 func (tri *Triangle) SetParent(s Shape) {
 	tri.Parent = s
+	forgetMediumOwners(tri)
+}
+
+func (tri *Triangle) mediumLink() *mediumLink {
+	return &tri.media
 }
 
 func (tri *Triangle) CastsShadow() bool {
@@ -36,6 +36,24 @@ func Includes(left Shape, target Shape) bool {
 	return false
 }
 
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+
+	return h
+}
+
 func IndexOf(objects []Shape, target Shape) (int, bool) {
 	for index, obj := range objects {
 		if obj.GetID() == target.GetID() {
@@ -0,0 +1,395 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+type VolumeMethod int
+
+const (
+	VolumeRayMarching VolumeMethod = iota
+	VolumeDeltaTracking
+)
+
+func (m VolumeMethod) String() string {
+	return [...]string{"ray-marching", "delta-tracking"}[m]
+}
+
+func ParseVolumeMethod(name string) (VolumeMethod, error) {
+	for _, m := range []VolumeMethod{VolumeRayMarching, VolumeDeltaTracking} {
+		if m.String() == name {
+			return m, nil
+		}
+	}
+
+	return VolumeRayMarching, fmt.Errorf("unknown volume method %q", name)
+}
+
+type DensityField interface {
+	DensityAt(point Tuple) float64
+}
+
+type BoundedDensity interface {
+	DensityBound() float64
+}
+
+type DensityFunc func(point Tuple) float64
+
+func (f DensityFunc) DensityAt(point Tuple) float64 {
+	return f(point)
+}
+
+type ConstantDensity float64
+
+func (d ConstantDensity) DensityAt(point Tuple) float64 {
+	return float64(d)
+}
+
+func (d ConstantDensity) DensityBound() float64 {
+	return math.Max(0, float64(d))
+}
+
+type NoiseDensity struct {
+	Scale     float64
+	Octaves   int
+	Threshold float64
+	Gain      float64
+}
+
+func NewNoiseDensity() NoiseDensity {
+	return NoiseDensity{
+		Scale:   1,
+		Octaves: 4,
+		Gain:    1,
+	}
+}
+
+func (d NoiseDensity) DensityAt(point Tuple) float64 {
+	n := FractalNoise(TupleScalarMultiply(point, d.Scale), d.Octaves)
+
+	return math.Max(0, n-d.Threshold) * d.Gain
+}
+
+func (d NoiseDensity) DensityBound() float64 {
+	amplitude := 2 * (1 - math.Pow(0.5, float64(maxInt(d.Octaves, 0))))
+
+	return math.Max(0, amplitude*perlinNoiseBound-d.Threshold) * math.Max(0, d.Gain)
+}
+
+type Volume struct {
+	ID               int64
+	Material         Material
+	Transform        Matrix
+	Inverse          Matrix
+	InverseTranspose Matrix
+	Motion           *Motion
+	Parent           Shape
+	media            mediumLink
+	HasShadow        bool
+
+	Bounds     BoundingBox
+	Density    DensityField
+	Absorption Color
+	Scattering Color
+	Anisotropy float64
+	MaxDensity float64
+	Method     VolumeMethod
+	StepSize   float64
+}
+
+func NewVolume(bounds BoundingBox, density DensityField) *Volume {
+	maxDensity := math.Inf(1)
+
+	if b, ok := density.(BoundedDensity); ok {
+		maxDensity = b.DensityBound()
+	}
+
+	return &Volume{
+		ID:               nextShapeID(),
+		Material:         NewDefaultMaterial(),
+		Transform:        NewIdentity4(),
+		Inverse:          NewIdentity4(),
+		InverseTranspose: NewIdentity4(),
+		Parent:           nil,
+		HasShadow:        true,
+		Bounds:           bounds,
+		Density:          density,
+		Absorption:       NewColor(0, 0, 0),
+		Scattering:       NewColor(1, 1, 1),
+		MaxDensity:       maxDensity,
+		Method:           VolumeRayMarching,
+		StepSize:         0.1,
+	}
+}
+
+func (v *Volume) GetID() int64 {
+	return v.ID
+}
+
+func (v *Volume) SetID(id int64) {
+	v.ID = id
+}
+
+func (v *Volume) LocalIntersect(localRay Ray) Intersections {
+	xtMin, xtMax := CheckAxis(localRay.Origin.X, localRay.Direction.X, v.Bounds.Min.X, v.Bounds.Max.X)
+	ytMin, ytMax := CheckAxis(localRay.Origin.Y, localRay.Direction.Y, v.Bounds.Min.Y, v.Bounds.Max.Y)
+	ztMin, ztMax := CheckAxis(localRay.Origin.Z, localRay.Direction.Z, v.Bounds.Min.Z, v.Bounds.Max.Z)
+
+	tMin := math.Max(math.Max(xtMin, ytMin), ztMin)
+	tMax := math.Min(math.Min(xtMax, ytMax), ztMax)
+
+	if tMin > tMax {
+		return Intersections{}
+	}
+
+	return NewIntersections(NewIntersection(tMin, v), NewIntersection(tMax, v))
+}
+
+func (v *Volume) LocalNormalAt(point Tuple, hit Intersection) Tuple {
+	return NewVector(0, 1, 0)
+}
+
+func (v *Volume) GetTransform() Matrix {
+	return v.Transform
+}
+
+func (v *Volume) SetTransform(transform Matrix) {
+	v.Transform = transform
+	v.Inverse = MatrixInverse(v.Transform)
+	v.InverseTranspose = MatrixTranspose(v.Inverse)
+}
+
+func (v *Volume) GetInverse() Matrix {
+	return v.Inverse
+}
+
+func (v *Volume) GetInverseTranspose() Matrix {
+	return v.InverseTranspose
+}
+
+func (v *Volume) GetMotion() *Motion {
+	return v.Motion
+}
+
+func (v *Volume) SetMotion(motion *Motion) {
+	v.Motion = motion
+}
+
+func (v *Volume) GetMaterial() Material {
+	return v.Material
+}
+
+func (v *Volume) SetMaterial(material Material) {
+	v.Material = material
+	forgetMediumOwners(v)
+}
+
+func (v *Volume) GetParent() Shape {
+	return v.Parent
+}
+
+func (v *Volume) SetParent(s Shape) {
+	v.Parent = s
+	forgetMediumOwners(v)
+}
+
+func (v *Volume) mediumLink() *mediumLink {
+	return &v.media
+}
+
+func (v *Volume) CastsShadow() bool {
+	return v.HasShadow
+}
+
+func (v *Volume) DensityAt(worldPoint Tuple) float64 {
+	return v.DensityAtTime(worldPoint, 0)
+}
+
+func (v *Volume) DensityAtTime(worldPoint Tuple, time float64) float64 {
+	point := WorldToObjectAtTime(v, worldPoint, time)
+
+	if !v.Bounds.ContainsPoint(point) {
+		return 0
+	}
+
+	return math.Max(0, v.Density.DensityAt(point))
+}
+
+func (v *Volume) Validate() error {
+	if v.Method == VolumeDeltaTracking && math.IsInf(v.MaxDensity, 1) {
+		return fmt.Errorf("delta tracking needs a max density for a density field without a known bound")
+	}
+
+	return nil
+}
+
+func validateVolumes(s Shape) error {
+	switch t := s.(type) {
+	case *Volume:
+		return t.Validate()
+	case *Group:
+		for _, child := range t.Children {
+			if err := validateVolumes(child); err != nil {
+				return err
+			}
+		}
+	case *CSG:
+		if err := validateVolumes(t.Left); err != nil {
+			return err
+		}
+
+		return validateVolumes(t.Right)
+	}
+
+	return nil
+}
+
+func (v *Volume) extinctionAt(point Tuple, time float64) (Color, Color) {
+	density := v.DensityAtTime(point, time)
+
+	if v.Method == VolumeDeltaTracking {
+		density = math.Min(density, v.MaxDensity)
+	}
+
+	return ColorScalarMultiply(AddColors(v.Absorption, v.Scattering), density), ColorScalarMultiply(v.Scattering, density)
+}
+
+func (v *Volume) majorant() float64 {
+	if math.IsInf(v.MaxDensity, 1) {
+		return 0
+	}
+
+	return v.MaxDensity * maxComponent(AddColors(v.Absorption, v.Scattering))
+}
+
+func (v *Volume) steps(r Ray, t0, t1 float64) int {
+	if v.StepSize <= 0 {
+		return 1
+	}
+
+	return minInt(maxInt(int(math.Ceil((t1-t0)*Magnitude(r.Direction)/v.StepSize)), 1), 1024)
+}
+
+func (v *Volume) transmittance(r Ray, t0, t1 float64, rng *rand.Rand) Color {
+	if v.Method == VolumeDeltaTracking {
+		return v.ratioTracking(r, t0, t1, rng)
+	}
+
+	speed := Magnitude(r.Direction)
+	n := v.steps(r, t0, t1)
+	dt := (t1 - t0) / float64(n)
+	var depth Color
+
+	for k := 0; k < n; k++ {
+		extinction, _ := v.extinctionAt(Position(r, t0+(float64(k)+0.5)*dt), r.Time)
+		depth = AddColors(depth, ColorScalarMultiply(extinction, dt*speed))
+	}
+
+	return NewColor(math.Exp(-depth.R), math.Exp(-depth.G), math.Exp(-depth.B))
+}
+
+func (v *Volume) ratioTracking(r Ray, t0, t1 float64, rng *rand.Rand) Color {
+	mu := v.majorant()
+
+	if mu <= 0 {
+		return white
+	}
+
+	speed := Magnitude(r.Direction)
+	transmittance := white
+
+	for t := t0; ; {
+		t -= math.Log(1-rng.Float64()) / (mu * speed)
+
+		if t >= t1 {
+			return transmittance
+		}
+
+		extinction, _ := v.extinctionAt(Position(r, t), r.Time)
+		transmittance = HadamardProduct(transmittance, NewColor(
+			math.Max(0, 1-extinction.R/mu),
+			math.Max(0, 1-extinction.G/mu),
+			math.Max(0, 1-extinction.B/mu),
+		))
+
+		if maxComponent(transmittance) <= 0 {
+			return transmittance
+		}
+	}
+}
+
+func (v *Volume) inScattering(w World, r Ray, t0, t1 float64, others func(t float64) Color, rng *rand.Rand) Color {
+	if v.Method == VolumeDeltaTracking {
+		return v.deltaTrackingInScattering(w, r, t0, t1, others, rng)
+	}
+
+	var total Color
+
+	speed := Magnitude(r.Direction)
+	direction := Normalize(r.Direction)
+	n := v.steps(r, t0, t1)
+	dt := (t1 - t0) / float64(n)
+	offset := rng.Float64()
+	transmittance := white
+
+	for k := 0; k < n; k++ {
+		t := t0 + (float64(k)+offset)*dt
+		point := Position(r, t)
+		extinction, scattering := v.extinctionAt(point, r.Time)
+
+		if maxComponent(scattering) > 0 {
+			scattered := HadamardProduct(scattering, lightScattering(w, point, direction, v.Anisotropy, rng))
+			total = AddColors(total, ColorScalarMultiply(HadamardProduct(HadamardProduct(transmittance, others(t)), scattered), dt*speed))
+		}
+
+		transmittance = HadamardProduct(transmittance, NewColor(
+			math.Exp(-extinction.R*dt*speed),
+			math.Exp(-extinction.G*dt*speed),
+			math.Exp(-extinction.B*dt*speed),
+		))
+	}
+
+	return total
+}
+
+func (v *Volume) deltaTrackingInScattering(w World, r Ray, t0, t1 float64, others func(t float64) Color, rng *rand.Rand) Color {
+	mu := v.majorant()
+
+	if mu <= 0 {
+		return black
+	}
+
+	var total Color
+
+	speed := Magnitude(r.Direction)
+	direction := Normalize(r.Direction)
+	n := v.steps(r, t0, t1)
+
+	for i := 0; i < n; i++ {
+		for t := t0; ; {
+			t -= math.Log(1-rng.Float64()) / (mu * speed)
+
+			if t >= t1 {
+				break
+			}
+
+			point := Position(r, t)
+			extinction, scattering := v.extinctionAt(point, r.Time)
+			sigma := maxComponent(extinction)
+
+			if rng.Float64()*mu >= sigma {
+				continue
+			}
+
+			albedo := ColorScalarDivide(scattering, sigma)
+			scattered := HadamardProduct(albedo, lightScattering(w, point, direction, v.Anisotropy, rng))
+			total = AddColors(total, HadamardProduct(others(t), scattered))
+
+			break
+		}
+	}
+
+	return ColorScalarDivide(total, float64(n))
+}
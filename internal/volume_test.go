@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func unitVolume(density DensityField) *Volume {
+	return NewVolume(NewBoundingBox(NewPoint(-1, -1, -1), NewPoint(1, 1, 1)), density)
+}
+
+func TestParseVolumeMethod(t *testing.T) {
+	for _, m := range []VolumeMethod{VolumeRayMarching, VolumeDeltaTracking} {
+		parsed, err := ParseVolumeMethod(m.String())
+		assert.Nil(t, err)
+		assert.Equal(t, m, parsed)
+	}
+
+	_, err := ParseVolumeMethod("photon-beams")
+	assert.NotNil(t, err)
+}
+
+func TestVolumeIntersectsItsBounds(t *testing.T) {
+	v := NewVolume(NewBoundingBox(NewPoint(-1, -2, -3), NewPoint(1, 2, 3)), ConstantDensity(1))
+	xs := Intersect(v, NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1)))
+
+	assert.Equal(t, 2, len(xs))
+	assert.Equal(t, 2.0, xs[0].T)
+	assert.Equal(t, 8.0, xs[1].T)
+	assert.Equal(t, v.Bounds, BoundsOf(v))
+	assert.Equal(t, 0, len(Intersect(v, NewRay(NewPoint(5, 0, -5), NewVector(0, 0, 1)))))
+}
+
+func TestNoiseIsDeterministicAndVaries(t *testing.T) {
+	assert.Equal(t, 0.0, PerlinNoise(1, 2, 3))
+	assert.Equal(t, PerlinNoise(0.3, 1.7, 2.2), PerlinNoise(0.3, 1.7, 2.2))
+
+	d := NewNoiseDensity()
+	d.Threshold = -1
+	a := d.DensityAt(NewPoint(0.25, 0.5, 0.75))
+	b := d.DensityAt(NewPoint(1.6, 0.1, 0.9))
+
+	assert.True(t, a > 0)
+	assert.NotEqual(t, a, b)
+}
+
+func TestConstantVolumeMatchesHomogeneousTransmittance(t *testing.T) {
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+	rng := rand.New(rand.NewSource(5))
+
+	v := unitVolume(ConstantDensity(1))
+	v.Scattering = NewColor(0, 0, 0)
+	v.Absorption = NewColor(0.5, 0.5, 0.5)
+
+	assert.True(t, ColorEquals(NewColor(math.Exp(-1), math.Exp(-1), math.Exp(-1)), v.transmittance(r, 4, 6, rng)))
+
+	v.Method = VolumeDeltaTracking
+	v.MaxDensity = 2
+
+	var sum Color
+	n := 4000
+
+	for i := 0; i < n; i++ {
+		sum = AddColors(sum, v.transmittance(r, 4, 6, rng))
+	}
+
+	assert.InDelta(t, math.Exp(-1), sum.R/float64(n), 0.02)
+}
+
+func TestVolumeDensityFollowsGroupTransforms(t *testing.T) {
+	v := unitVolume(DensityFunc(func(p Tuple) float64 {
+		if p.X > 0 {
+			return 1
+		}
+
+		return 0
+	}))
+
+	g := NewGroup()
+	g.SetTransform(Translate(10, 0, 0))
+	g.AddChild(v)
+
+	assert.Equal(t, 1.0, v.DensityAt(NewPoint(10.5, 0, 0)))
+	assert.Equal(t, 0.0, v.DensityAt(NewPoint(9.5, 0, 0)))
+	assert.Equal(t, 0.0, v.DensityAt(NewPoint(0.5, 0, 0)))
+}
+
+func TestVolumeIsNotASurfaceButCastsShadows(t *testing.T) {
+	v := unitVolume(ConstantDensity(1))
+	v.Scattering = NewColor(0.5, 0.5, 0.5)
+
+	w := NewWorld()
+	light := NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1))
+	w.Lights = append(w.Lights, light)
+	w.Objects = append(w.Objects, v)
+
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+
+	assert.Equal(t, Intersection{}, Hit(IntersectWorld(w, r)))
+	assert.InDelta(t, math.Exp(-1), IntensityAt(light, NewPoint(0, 0, 5), w).G, 1e-4)
+	assert.InDelta(t, 1.0, IntensityAt(light, NewPoint(3, 0, 5), w).G, 1e-4)
+}
+
+func TestVolumeScattersLightTowardsCamera(t *testing.T) {
+	for _, method := range []VolumeMethod{VolumeRayMarching, VolumeDeltaTracking} {
+		v := unitVolume(ConstantDensity(1))
+		v.Scattering = NewColor(0.5, 0.5, 0.5)
+		v.Method = method
+
+		w := NewWorld()
+		w.Lights = append(w.Lights, NewPointLight(NewPoint(0, 10, 0), NewColor(1, 1, 1)))
+		w.Objects = append(w.Objects, v)
+		w = w.WithRand(rand.New(rand.NewSource(9)))
+
+		through := ColorAt(w, NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1)), RecursionDepth)
+		past := ColorAt(w, NewRay(NewPoint(3, 0, -5), NewVector(0, 0, 1)), RecursionDepth)
+
+		assert.True(t, through.R > 0, method.String())
+		assert.True(t, ColorEquals(black, past), method.String())
+	}
+}
+
+func TestVolumeMaxDensityBoundsTheDensityField(t *testing.T) {
+	assert.Equal(t, 3.0, unitVolume(ConstantDensity(3)).MaxDensity)
+
+	noise := NewNoiseDensity()
+	noise.Gain = 3
+	noise.Threshold = -0.5
+	bound := unitVolume(noise).MaxDensity
+	rng := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 20000; i++ {
+		p := NewPoint(rng.Float64()*64, rng.Float64()*64, rng.Float64()*64)
+		assert.LessOrEqual(t, noise.DensityAt(p), bound)
+	}
+
+	v := unitVolume(DensityFunc(func(p Tuple) float64 {
+		return 5
+	}))
+
+	assert.True(t, math.IsInf(v.MaxDensity, 1))
+	assert.Equal(t, 5.0, v.DensityAt(NewPoint(0, 0, 0)))
+	assert.Nil(t, v.Validate())
+
+	v.Method = VolumeDeltaTracking
+	assert.NotNil(t, v.Validate())
+
+	w := NewWorld()
+	w.Objects = append(w.Objects, v)
+	_, _, err := RenderPasses(context.Background(), NewCamera(2, 2, math.Pi/2), w, DefaultRenderOptions())
+	assert.NotNil(t, err)
+
+	v.MaxDensity = 2
+	assert.Nil(t, v.Validate())
+	extinction, _ := v.extinctionAt(NewPoint(0, 0, 0), 0)
+	assert.Equal(t, 2.0, extinction.G)
+	assert.Equal(t, 5.0, v.DensityAt(NewPoint(0, 0, 0)))
+}
@@ -1,12 +1,24 @@
 package internal
 
+import (
+	"math/rand"
+	"sync"
+)
+
 type World struct {
-	Lights  []LightSource
-	Objects []Shape
+	Lights              []LightSource
+	Objects             []Shape
+	Background          Background
+	Environment         *EnvironmentLight
+	Medium              *Medium
+	Caustics            *PhotonMap
+	TransmissiveShadows bool
+	Time                float64
+	rng                 *rand.Rand
 }
 
 func NewWorld() World {
-	return World{}
+	return World{rng: rand.New(rand.NewSource(0))}
 }
 
 func NewDefaultWorld() World {
@@ -31,6 +43,7 @@ func NewDefaultWorld() World {
 	s2.SetTransform(Scale(0.5, 0.5, 0.5))
 
 	return World{
+		rng:    rand.New(rand.NewSource(0)),
 		Lights: lights,
 		Objects: []Shape{
 			s1,
@@ -39,6 +52,78 @@ func NewDefaultWorld() World {
 	}
 }
 
+func (w World) AssignShapeIDs() {
+	var id int64
+	var assign func(s Shape)
+
+	assign = func(s Shape) {
+		id++
+		s.SetID(id)
+
+		switch t := s.(type) {
+		case *Group:
+			for _, child := range t.Children {
+				assign(child)
+			}
+		case *CSG:
+			assign(t.Left)
+			assign(t.Right)
+		}
+	}
+
+	for _, obj := range w.Objects {
+		assign(obj)
+	}
+}
+
+func (w World) WithRand(rng *rand.Rand) World {
+	w.rng = rng
+	return w
+}
+
+func (w World) Rand() *rand.Rand {
+	if w.rng == nil {
+		return sharedRand
+	}
+
+	return w.rng
+}
+
+var sharedRand = rand.New(&lockedSource{source: rand.NewSource(0).(rand.Source64)})
+
+type lockedSource struct {
+	mu     sync.Mutex
+	source rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.source.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.source.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.source.Seed(seed)
+}
+
+func (w World) ShadowRay(point, direction Tuple) Ray {
+	r := NewRay(point, direction)
+	r.Time = w.Time
+
+	return r
+}
+
 func IntersectWorld(w World, r Ray) Intersections {
 	var intersects []Intersection
 
@@ -58,7 +143,7 @@ func IsShadowed(world World, lightPos, point Tuple) bool {
 	distance := Magnitude(v)
 	direction := Normalize(v)
 
-	r := NewRay(point, direction)
+	r := world.ShadowRay(point, direction)
 	intersections := IntersectWorld(world, r)
 	h := Hit(intersections)
 	empty := Intersection{}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -68,3 +69,46 @@ func TestIsShadowedTestForOcclusionBetweenTwoPoints(t *testing.T) {
 		assert.Equal(t, test.result, IsShadowed(w, lightPos, test.point))
 	}
 }
+
+func TestWorldWithRandIsScopedToCopy(t *testing.T) {
+	w := NewDefaultWorld()
+	rng := rand.New(rand.NewSource(3))
+	seeded := w.WithRand(rng)
+
+	assert.True(t, rng == seeded.Rand())
+	assert.True(t, rng != w.Rand())
+	assert.True(t, w.Rand() == w.Rand())
+	assert.NotEqual(t, w.Rand().Int63(), w.Rand().Int63())
+}
+
+func TestZeroWorldRendersWithRandomisedLighting(t *testing.T) {
+	sphere := NewSphere()
+	w := World{
+		Lights:  []LightSource{NewAreaLight(NewPoint(-1, 2, -4), NewVector(2, 0, 0), 4, NewVector(0, 2, 0), 4, NewColor(1, 1, 1))},
+		Objects: []Shape{sphere},
+		Medium:  NewMedium(NewColor(0.01, 0.01, 0.01), NewColor(0.05, 0.05, 0.05), 0),
+	}
+
+	assert.NotNil(t, w.Rand())
+	assert.True(t, w.Rand() == World{}.Rand())
+	assert.NotPanics(t, func() {
+		ColorAt(w, NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1)), RecursionDepth)
+	})
+}
+
+func TestAssignShapeIDsNumbersSceneOrder(t *testing.T) {
+	w := NewWorld()
+	g := NewGroup()
+	child := NewSphere()
+	g.AddChild(child)
+	csg := NewCSG(CSGUnion, NewCube(), NewCylinder())
+	w.Objects = []Shape{NewPlane(), g, csg}
+
+	w.AssignShapeIDs()
+
+	assert.Equal(t, int64(1), w.Objects[0].GetID())
+	assert.Equal(t, int64(2), g.GetID())
+	assert.Equal(t, int64(3), child.GetID())
+	assert.Equal(t, int64(4), csg.GetID())
+	assert.Equal(t, int64(6), csg.Right.GetID())
+}
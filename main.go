@@ -1,1138 +1,954 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"gotracer/internal"
+	"gotracer/parser"
 	"image"
 	"image/png"
+	"io"
+	"io/ioutil"
 	"math"
+	"math/rand"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func renderCircle() {
-	rayOrigin := internal.NewPoint(0.0, 0.0, -5.0)
-	wallZ := 10.0
+const usage = `Usage: gotracer <command> [flags]
 
-	wallSize := 7.0
-	canvasPixels := 500
-	pixelSize := wallSize / float64(canvasPixels)
-	half := wallSize / 2
+Commands:
+  render   Render a scene file or built-in scene to an image
+  scenes   List the built-in scenes
+  info     Describe a scene without rendering it
 
-	canvas := internal.NewCanvas(canvasPixels, canvasPixels)
-	color := internal.NewColor(1, 0, 0)
-	shape := internal.NewSphere()
+Run "gotracer <command> -h" for the flags of a command.
+`
 
-	for y := 0; y < canvasPixels-1; y++ {
-		worldY := half - pixelSize*float64(y)
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "render":
+		return runRender(args[1:], stdout, stderr)
+	case "scenes":
+		return runScenes(args[1:], stdout, stderr)
+	case "info":
+		return runInfo(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "gotracer: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+
+		return exitUsage, false
+	}
+
+	return exitOK, true
+}
+
+func sceneArgument(fs *flag.FlagSet, sceneFlag string, stderr io.Writer) (string, bool) {
+	ref := sceneFlag
+
+	if ref == "" && fs.NArg() > 0 {
+		ref = fs.Arg(0)
+	}
+
+	if ref == "" || fs.NArg() > 1 || (sceneFlag != "" && fs.NArg() > 0) {
+		fmt.Fprintf(stderr, "gotracer %s: expected exactly one scene file or built-in scene name\n", fs.Name())
+		fs.Usage()
+		return "", false
+	}
+
+	return ref, true
+}
+
+type renderFlags struct {
+	scene               string
+	output              string
+	width               int
+	height              int
+	fov                 float64
+	projection          string
+	viewWidth           float64
+	aperture            float64
+	focalDistance       float64
+	blades              int
+	autofocus           bool
+	shutterOpen         float64
+	shutterClose        float64
+	stereo              string
+	interocular         float64
+	convergence         float64
+	depth               int
+	workers             int
+	tileSize            int
+	samples             int
+	sampler             string
+	filter              string
+	filterRadius        float64
+	adaptive            bool
+	threshold           float64
+	adaptiveDepth       int
+	maxSamples          int
+	integrator          string
+	maxBounces          int
+	rouletteStart       int
+	aovs                string
+	transparent         bool
+	photons             int
+	photonRadius        float64
+	transmissiveShadows bool
+	spectral            bool
+	wavelengths         int
+	seed                int64
+	timeout             time.Duration
+	frames              string
+	fps                 float64
+	progress            bool
+}
 
-		for x := 0; x < canvasPixels-1; x++ {
-			worldX := -half + pixelSize*float64(x)
+const unsetFlag = -1
+
+func newRenderFlags(fs *flag.FlagSet) *renderFlags {
+	defaults := internal.DefaultRenderOptions()
+	f := &renderFlags{}
+
+	fs.StringVar(&f.scene, "scene", "", "scene file (.yml/.yaml) or built-in scene name")
+	fs.StringVar(&f.output, "o", "", "output image path, .png or .ppm (default <scene>.png)")
+	fs.IntVar(&f.width, "width", 0, "image width in pixels (default from scene)")
+	fs.IntVar(&f.height, "height", 0, "image height in pixels (default from scene)")
+	fs.Float64Var(&f.fov, "fov", 0, "horizontal field of view in radians (default from scene)")
+	fs.StringVar(&f.projection, "projection", "", "camera projection: "+projectionNames()+" (default from scene)")
+	fs.Float64Var(&f.viewWidth, "view-width", 0, "world-space width of the orthographic view plane (default from scene)")
+	fs.Float64Var(&f.aperture, "aperture", unsetFlag, "thin-lens aperture radius in world units (default from scene, 0 is a pinhole)")
+	fs.Float64Var(&f.focalDistance, "focal-distance", 0, "distance to the plane in focus (default from scene)")
+	fs.IntVar(&f.blades, "blades", 0, "aperture blade count for polygonal bokeh (default from scene, 0 is a round disk)")
+	fs.BoolVar(&f.autofocus, "autofocus", false, "focus on the first object under the image centre")
+	fs.Float64Var(&f.shutterOpen, "shutter-open", unsetFlag, "frame time in [0, 1] at which the shutter opens for motion blur (default from scene)")
+	fs.Float64Var(&f.shutterClose, "shutter-close", unsetFlag, "frame time in [0, 1] at which the shutter closes for motion blur (default from scene)")
+	fs.StringVar(&f.stereo, "stereo", "", "render a stereo pair: "+stereoLayoutNames()+" (default renders a single view)")
+	fs.Float64Var(&f.interocular, "interocular", 0.065, "distance between the stereo eyes in world units")
+	fs.Float64Var(&f.convergence, "convergence", 0, "distance at which the stereo eyes converge (default focal distance, else the object under the image centre)")
+	fs.IntVar(&f.depth, "depth", internal.RecursionDepth, "maximum reflection/refraction recursion depth")
+	fs.IntVar(&f.workers, "workers", runtime.NumCPU(), "number of render workers")
+	fs.IntVar(&f.tileSize, "tile", defaults.TileSize, "edge length in pixels of the tiles handed to workers")
+	fs.IntVar(&f.samples, "samples", 1, "camera rays per pixel, rounded up to a square number")
+	fs.StringVar(&f.sampler, "sampler", internal.SampleStratified.String(), "subpixel sample placement: stratified or jittered")
+	fs.StringVar(&f.filter, "filter", internal.FilterBox.String(), "reconstruction filter: box, tent, gaussian or mitchell")
+	fs.Float64Var(&f.filterRadius, "filter-radius", 0, "reconstruction filter radius in pixels (default depends on filter)")
+	fs.BoolVar(&f.adaptive, "adaptive", false, "adaptively subdivide pixels with high local contrast instead of fixed supersampling")
+	fs.Float64Var(&f.threshold, "threshold", defaults.AdaptiveThreshold, "adaptive contrast threshold")
+	fs.IntVar(&f.adaptiveDepth, "adaptive-depth", defaults.AdaptiveDepth, "maximum adaptive subdivision depth")
+	fs.IntVar(&f.maxSamples, "max-samples", defaults.MaxSamples, "maximum adaptive samples per pixel")
+	fs.StringVar(&f.integrator, "integrator", "whitted", "shading integrator: "+strings.Join(internal.IntegratorNames(), ", "))
+	fs.IntVar(&f.maxBounces, "max-bounces", defaults.MaxBounces, "maximum path tracing bounces")
+	fs.IntVar(&f.rouletteStart, "roulette", defaults.RouletteStart, "bounce after which Russian roulette may terminate paths")
+	fs.StringVar(&f.aovs, "aov", "", "comma-separated extra passes written next to the image ("+aovNames()+" or all); lighting passes always use the Whitted decomposition")
+	fs.BoolVar(&f.transparent, "transparent", false, "make camera rays that miss every object transparent in the PNG alpha channel")
+	fs.IntVar(&f.photons, "photons", 0, "caustic photons emitted from the lights before rendering (0 disables caustics)")
+	fs.Float64Var(&f.photonRadius, "photon-radius", defaults.PhotonRadius, "caustic photon gather radius in world units")
+	fs.BoolVar(&f.transmissiveShadows, "transmissive-shadows", false, "let shadow rays pass through transparent objects, tinted by their colour and absorption")
+	fs.BoolVar(&f.spectral, "spectral", false, "trace wavelengths instead of RGB so dispersive materials split light")
+	fs.IntVar(&f.wavelengths, "wavelengths", defaults.Wavelengths, "wavelengths traced per camera ray in spectral mode")
+	fs.Int64Var(&f.seed, "seed", 0, "seed for all stochastic sampling; equal seeds give identical images")
+	fs.DurationVar(&f.timeout, "timeout", 0, "abort the render after this long and write the partial image (0 means no limit)")
+	fs.StringVar(&f.frames, "frames", "", "render an animation as a numbered image sequence, N or FIRST-LAST inclusive (default the keyframed duration of the scene, else a still at time 0)")
+	fs.Float64Var(&f.fps, "fps", 24, "animation frames per second; frame N is rendered at scene time N/fps")
+	fs.BoolVar(&f.progress, "progress", false, "print progress to stderr")
+
+	return f
+}
 
-			position := internal.NewPoint(worldX, worldY, wallZ)
-			r := internal.NewRay(rayOrigin, internal.SubTuples(position, rayOrigin).Normalize())
-			xs := internal.Intersect(shape, r)
-			empty := internal.Intersection{}
+func (f *renderFlags) check() error {
+	checks := []struct {
+		failed  bool
+		message string
+	}{
+		{f.width < 0, "-width must not be negative"},
+		{f.height < 0, "-height must not be negative"},
+		{f.fov < 0, "-fov must not be negative"},
+		{f.viewWidth < 0, "-view-width must not be negative"},
+		{f.aperture < 0 && f.aperture != unsetFlag, "-aperture must not be negative"},
+		{f.focalDistance < 0, "-focal-distance must not be negative"},
+		{f.blades < 0 || (f.blades > 0 && f.blades < 3), "-blades must be 0 or at least 3"},
+		{(f.shutterOpen < 0 && f.shutterOpen != unsetFlag) || f.shutterOpen > 1, "-shutter-open must be in [0, 1]"},
+		{(f.shutterClose < 0 && f.shutterClose != unsetFlag) || f.shutterClose > 1, "-shutter-close must be in [0, 1]"},
+		{f.interocular <= 0, "-interocular must be positive"},
+		{f.convergence < 0, "-convergence must not be negative"},
+		{f.depth < 0, "-depth must not be negative"},
+		{f.workers < 1, "-workers must be at least 1"},
+		{f.tileSize < 1, "-tile must be at least 1"},
+		{f.samples < 1, "-samples must be at least 1"},
+		{f.filterRadius < 0, "-filter-radius must not be negative"},
+		{f.threshold < 0, "-threshold must not be negative"},
+		{f.adaptiveDepth < 0, "-adaptive-depth must not be negative"},
+		{f.maxSamples < 1, "-max-samples must be at least 1"},
+		{f.maxBounces < 1, "-max-bounces must be at least 1"},
+		{f.rouletteStart < 0, "-roulette must not be negative"},
+		{f.photons < 0, "-photons must not be negative"},
+		{f.photonRadius <= 0, "-photon-radius must be positive"},
+		{f.wavelengths < 1, "-wavelengths must be at least 1"},
+		{f.timeout < 0, "-timeout must not be negative"},
+		{f.fps <= 0, "-fps must be positive"},
+	}
 
-			if internal.Hit(xs) != empty {
-				canvas.WritePixelAtCoord(x, y, color)
-			}
+	for _, c := range checks {
+		if c.failed {
+			return errors.New(c.message)
 		}
 	}
 
-	writeToPng(canvas, "circle.png")
+	return nil
 }
 
-func renderSphere() {
-	rayOrigin := internal.NewPoint(0.0, 0.0, -5.0)
-	wallZ := 10.0
+type renderSettings struct {
+	flags         *renderFlags
+	layout        internal.StereoLayout
+	projection    internal.Projection
+	hasProjection bool
+	sampling      internal.SamplePattern
+	filter        internal.PixelFilter
+	opts          internal.RenderOptions
+	first         int
+	last          int
+}
 
-	wallSize := 7.0
-	canvasPixels := 1024
-	pixelSize := wallSize / float64(canvasPixels)
-	half := wallSize / 2
+func newRenderSettings(f *renderFlags) (renderSettings, error) {
+	s := renderSettings{flags: f}
 
-	canvas := internal.NewCanvas(canvasPixels, canvasPixels)
-	shape := internal.NewSphere()
-	shape.Material.SetColor(internal.NewColor(0.0, 0.2, 1))
+	if err := f.check(); err != nil {
+		return s, err
+	}
 
-	lightPosition := internal.NewPoint(-10, 10, -10)
-	lightColor := internal.NewColor(1, 1, 1)
-	light := internal.NewPointLight(lightPosition, lightColor)
+	if f.adaptive && (f.samples > 1 || f.sampler != internal.SampleStratified.String() || f.filter != internal.FilterBox.String() || f.filterRadius > 0) {
+		return s, errors.New("-adaptive places its own samples and cannot be combined with -samples, -sampler, -filter or -filter-radius")
+	}
 
-	for y := 0; y < canvasPixels-1; y++ {
-		worldY := half - pixelSize*float64(y)
+	if f.adaptive && f.maxSamples < 5 {
+		return s, errors.New("-max-samples must be at least 5 with -adaptive")
+	}
 
-		for x := 0; x < canvasPixels-1; x++ {
-			worldX := -half + pixelSize*float64(x)
+	var err error
 
-			position := internal.NewPoint(worldX, worldY, wallZ)
-			r := internal.NewRay(rayOrigin, internal.SubTuples(position, rayOrigin).Normalize())
-			xs := internal.Intersect(shape, r)
-			empty := internal.Intersection{}
+	if s.first, s.last, err = parseFrames(f.frames); err != nil {
+		return s, errors.New("-frames must be N or FIRST-LAST with 0 <= FIRST <= LAST")
+	}
 
-			if hit := internal.Hit(xs); hit != empty {
-				point := internal.Position(r, hit.T)
-				normal := internal.NormalAt(hit.Object, point, internal.Intersection{})
-				eye := internal.Negate(r.Direction)
-				color := internal.Lighting(hit.Object.GetMaterial(), internal.NewSphere(), light, point, eye, normal, 1.0)
+	if f.stereo != "" {
+		if s.layout, err = internal.ParseStereoLayout(f.stereo); err != nil {
+			return s, err
+		}
+	}
 
-				canvas.WritePixelAtCoord(x, y, color)
-			}
+	if f.projection != "" {
+		if s.projection, err = internal.ParseProjection(f.projection); err != nil {
+			return s, err
 		}
+
+		s.hasProjection = true
+	}
+
+	if s.sampling, err = internal.ParseSamplePattern(f.sampler); err != nil {
+		return s, err
+	}
+
+	if s.filter, err = internal.ParsePixelFilter(f.filter); err != nil {
+		return s, err
 	}
 
-	writeToPng(canvas, "sphere.png")
+	aovs, err := parseAOVs(f.aovs)
+
+	if err != nil {
+		return s, err
+	}
+
+	s.opts = internal.DefaultRenderOptions()
+	s.opts.Depth = f.depth
+	s.opts.Workers = f.workers
+	s.opts.TileSize = f.tileSize
+	s.opts.Adaptive = f.adaptive
+	s.opts.AdaptiveThreshold = f.threshold
+	s.opts.AdaptiveDepth = f.adaptiveDepth
+	s.opts.MaxSamples = f.maxSamples
+	s.opts.AOVs = aovs
+	s.opts.Seed = f.seed
+	s.opts.TransparentBackground = f.transparent
+	s.opts.MaxBounces = f.maxBounces
+	s.opts.RouletteStart = f.rouletteStart
+	s.opts.Photons = f.photons
+	s.opts.PhotonRadius = f.photonRadius
+	s.opts.Spectral = f.spectral
+	s.opts.Wavelengths = f.wavelengths
+
+	if s.opts.Integrator, err = internal.NewIntegrator(f.integrator, s.opts); err != nil {
+		return s, err
+	}
+
+	return s, nil
 }
 
-func renderScene() {
-	world := internal.NewWorld()
-
-	floor := internal.NewSphere()
-	floor.SetTransform(internal.Scale(10, 0.1, 10))
-	floor.SetMaterial(internal.NewDefaultMaterial())
-	floor.Material.SetColor(internal.NewColor(1, 0.9, 0.9))
-	floor.Material.Specular = 0
-	world.Objects = append(world.Objects, floor)
-
-	leftWall := internal.NewSphere()
-	leftTransform := internal.MatrixMultiply(
-		internal.MatrixMultiply(
-			internal.MatrixMultiply(
-				internal.Translate(0, 0, 5),
-				internal.RotateY(-math.Pi/4),
-			),
-			internal.RotateX(math.Pi/2),
-		),
-		internal.Scale(10, 0.1, 10),
-	)
-	leftWall.SetTransform(leftTransform)
-	world.Objects = append(world.Objects, leftWall)
-
-	rightWall := internal.NewSphere()
-	rightTransform := internal.MatrixMultiply(
-		internal.MatrixMultiply(
-			internal.MatrixMultiply(
-				internal.Translate(0, 0, 5),
-				internal.RotateY(math.Pi/4),
-			),
-			internal.RotateX(math.Pi/2),
-		),
-		internal.Scale(10, 0.1, 10),
-	)
-	rightWall.SetTransform(rightTransform)
-	world.Objects = append(world.Objects, rightWall)
-
-	middle := internal.NewSphere()
-	middle.SetTransform(internal.Translate(-0.5, 1, 0.5))
-	middle.SetMaterial(internal.NewDefaultMaterial())
-	middle.Material.SetColor(internal.NewColor(0.1, 1, 0.5))
-	middle.Material.Diffuse = 0.7
-	middle.Material.Specular = 0.3
-	world.Objects = append(world.Objects, middle)
-
-	right := internal.NewSphere()
-	right.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(1.5, 0.5, -0.5),
-			internal.Scale(0.5, 0.5, 0.5),
-		),
-	)
-	right.SetMaterial(internal.NewDefaultMaterial())
-	right.Material.SetColor(internal.NewColor(0.5, 1, 0.1))
-	right.Material.Diffuse = 0.7
-	right.Material.Specular = 0.3
-	world.Objects = append(world.Objects, right)
-
-	left := internal.NewSphere()
-	left.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-1.5, 0.33, -0.75),
-			internal.Scale(0.33, 0.33, 0.33),
-		),
-	)
-	left.SetMaterial(internal.NewDefaultMaterial())
-	left.Material.SetColor(internal.NewColor(1.0, 0.8, 0.1))
-	left.Material.Diffuse = 0.7
-	left.Material.Specular = 0.3
-	world.Objects = append(world.Objects, left)
-
-	world.Lights = append(world.Lights, internal.NewPointLight(internal.NewPoint(-10, 10, -10), internal.NewColor(1, 1, 1)))
-
-	camera := internal.NewCamera(1920, 1080, math.Pi/3)
-	camera.Transform = internal.ViewTransform(
-		internal.NewPoint(0, 1.5, -5),
-		internal.NewPoint(0, 1, 0),
-		internal.NewVector(0, 1, 0),
-	)
-
-	canvas := internal.Render(camera, world)
-	writeToPng(canvas, "scene.png")
+func runRender(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	f := newRenderFlags(fs)
+
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	ref, ok := sceneArgument(fs, f.scene, stderr)
+
+	if !ok {
+		return exitUsage
+	}
+
+	settings, err := newRenderSettings(f)
+
+	if err != nil {
+		fmt.Fprintf(stderr, "gotracer render: %v\n", err)
+		return exitUsage
+	}
+
+	if _, whitted := settings.opts.Integrator.(internal.WhittedIntegrator); !whitted && hasLightingAOV(settings.opts.AOVs) {
+		fmt.Fprintf(stderr, "gotracer render: lighting passes are a Whitted decomposition and will not sum to the %s image\n", f.integrator)
+	}
+
+	outputPath := f.output
+
+	if outputPath == "" {
+		outputPath = sceneBaseName(ref) + ".png"
+	}
+
+	ctx, cancel := renderContext(f.timeout)
+	defer cancel()
+
+	source, err := openScene(ref)
+
+	if err != nil {
+		fmt.Fprintf(stderr, "gotracer render: %v\n", err)
+		return exitFailure
+	}
+
+	first, last := settings.first, settings.last
+
+	if f.frames == "" {
+		scene, err := source(0, 0)
+
+		if err != nil {
+			fmt.Fprintf(stderr, "gotracer render: %v\n", err)
+			return exitFailure
+		}
+
+		if scene.Duration == 0 {
+			return renderScene(ctx, scene, settings, outputPath, stdout, stderr)
+		}
+
+		first, last = 0, int(math.Round(scene.Duration*f.fps))
+	}
+
+	for frame := first; frame <= last; frame++ {
+		scene, err := source(float64(frame)/f.fps, 1/f.fps)
+
+		if err != nil {
+			fmt.Fprintf(stderr, "gotracer render: %v\n", err)
+			return exitFailure
+		}
+
+		if code := renderScene(ctx, scene, settings, passPath(outputPath, fmt.Sprintf("%04d", frame)), stdout, stderr); code != exitOK {
+			return code
+		}
+	}
+
+	return exitOK
 }
 
-func renderRefraction() {
-	world := internal.NewWorld()
-
-	camera := internal.NewCamera(1920, 1080, 0.5)
-	camera.Transform = internal.ViewTransform(internal.NewPoint(-4.5, 0.85, -4), internal.NewPoint(0, 0.85, 0), internal.NewVector(0, 1, 0))
-
-	wallMaterial := internal.NewDefaultMaterial()
-	pattern := internal.NewCheckersPattern(internal.NewColor(0, 0, 0), internal.NewColor(0.75, 0.75, 0.74))
-	pattern.SetTransform(internal.Scale(0.5, 0.5, 0.5))
-	wallMaterial.SetPattern(pattern)
-	wallMaterial.Specular = 0.0
-
-	floor := internal.NewPlane()
-	floor.SetTransform(internal.RotateY(0.31415))
-	floorMaterial := internal.NewDefaultMaterial()
-	floorMaterial.SetPattern(pattern)
-	floorMaterial.Ambient = 0.5
-	floorMaterial.Diffuse = 0.4
-	floorMaterial.Specular = 0.8
-	floorMaterial.Reflective = 0.1
-	floor.SetMaterial(floorMaterial)
-
-	ceil := internal.NewPlane()
-	ceil.SetTransform(internal.Translate(0, 5, 0))
-	ceilMaterial := internal.NewDefaultMaterial()
-	ceilPattern := internal.NewCheckersPattern(internal.NewColor(0.85, 0.85, 0.85), internal.NewColor(1, 1, 1))
-	ceilPattern.SetTransform(internal.Scale(0.2, 0.2, 0.2))
-	ceilMaterial.SetPattern(ceilPattern)
-	ceilMaterial.Ambient = 0.5
-	ceilMaterial.Specular = 0
-	ceil.SetMaterial(ceilMaterial)
-
-	westWall := internal.NewPlane()
-	westWall.SetTransform(
-		internal.MatrixMultiply(
-			internal.MatrixMultiply(
-				internal.Translate(-5, 0, 0),
-				internal.RotateZ(1.5708),
-			),
-			internal.RotateY(1.5708),
-		))
-
-	westWall.SetMaterial(wallMaterial)
-
-	eastWall := internal.NewPlane()
-	eastWall.SetTransform(
-		internal.MatrixMultiply(
-			internal.MatrixMultiply(
-				internal.Translate(5, 0, 0),
-				internal.RotateZ(1.5708),
-			),
-			internal.RotateY(1.5708),
-		))
-	eastWall.SetMaterial(wallMaterial)
-
-	northWall := internal.NewPlane()
-	northWall.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0, 0, 5),
-			internal.RotateX(1.5708),
-		),
-	)
-	northWall.SetMaterial(wallMaterial)
-
-	southWall := internal.NewPlane()
-	southWall.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0, 0, -5),
-			internal.RotateX(1.5708),
-		),
-	)
-	southWall.SetMaterial(wallMaterial)
-
-	ball1 := internal.NewSphere()
-	ball1.SetTransform(internal.Translate(4, 1, 4))
-	material1 := internal.NewDefaultMaterial()
-	material1.SetColor(internal.NewColor(0.8, 0.1, 0.3))
-	material1.Specular = 0
-	ball1.SetMaterial(material1)
-
-	ball2 := internal.NewSphere()
-	ball2.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(4.6, 0.4, 2.9),
-			internal.Scale(0.4, 0.4, 0.4),
-		),
-	)
-	material2 := internal.NewDefaultMaterial()
-	material2.SetColor(internal.NewColor(0.1, 0.8, 0.2))
-	material2.Shininess = 200
-	ball2.SetMaterial(material2)
-
-	ball3 := internal.NewSphere()
-	ball3.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(2.6, 0.6, 4.4),
-			internal.Scale(0.6, 0.6, 0.6),
-		),
-	)
-	material3 := internal.NewDefaultMaterial()
-	material3.SetColor(internal.NewColor(0.2, 0.1, 0.8))
-	material3.Shininess = 10
-	material3.Specular = 0.4
-	ball3.SetMaterial(material3)
-
-	glassBall := internal.NewSphere()
-	glassBall.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0.25, 1, 0),
-			internal.Scale(1, 1, 1),
-		),
-	)
-
-	glassMaterial := internal.NewMaterial(internal.NewColor(0.8, 0.8, 0.9), nil, 0, 0.2, 0.9, 300, 0.0, 0.8, 1.5)
-	glassBall.SetMaterial(glassMaterial)
-
-	world.Lights = append(world.Lights, internal.NewPointLight(internal.NewPoint(-4.9, 4.9, 1), internal.NewColor(1, 1, 1)))
-	world.Objects = append(world.Objects, ceil, floor, northWall, eastWall, southWall, westWall, ball1, ball2, ball3, glassBall)
-
-	canvas := internal.Render(camera, world)
-	writeToPng(canvas, "refraction.png")
+func renderScene(ctx context.Context, scene *parser.Scene, s renderSettings, outputPath string, stdout, stderr io.Writer) int {
+	world := scene.World
+	world.TransmissiveShadows = world.TransmissiveShadows || s.flags.transmissiveShadows
+
+	cameras, code, ok := sceneCameras(scene.Camera, world, s, stderr)
+
+	if !ok {
+		return code
+	}
+
+	if s.opts.Photons > 0 {
+		var err error
+
+		if world.Caustics, err = internal.BuildCausticMap(ctx, world, s.opts.Photons, s.opts.PhotonRadius, rand.New(rand.NewSource(s.opts.Seed))); err != nil {
+			fmt.Fprintf(stderr, "gotracer render: render aborted (%v)\n", err)
+			return exitFailure
+		}
+	}
+
+	outputs, totalSamples, elapsed, renderErr := renderViews(ctx, cameras, world, s, outputPath, stderr)
+
+	if len(outputs) == 2 {
+		var err error
+
+		if outputs, err = stereoOutputs(outputs[0], outputs[1], s.layout); err != nil {
+			fmt.Fprintf(stderr, "gotracer render: %v\n", err)
+			return exitFailure
+		}
+	}
+
+	var paths []string
+
+	for _, out := range outputs {
+		if err := writeImage(out.canvas, out.path); err != nil {
+			fmt.Fprintf(stderr, "gotracer render: %v\n", err)
+			return exitFailure
+		}
+
+		if err := writePasses(out.passes, out.path); err != nil {
+			fmt.Fprintf(stderr, "gotracer render: %v\n", err)
+			return exitFailure
+		}
+
+		paths = append(paths, out.path)
+	}
+
+	written := strings.Join(paths, ", ")
+
+	if renderErr != nil {
+		fmt.Fprintf(stderr, "gotracer render: render aborted (%v), wrote partial image to %s\n", renderErr, written)
+		return exitFailure
+	}
+
+	fmt.Fprintf(stdout, "wrote %s (%dx%d, %d samples in %s)\n",
+		written, outputs[0].canvas.W, outputs[0].canvas.H, totalSamples, elapsed.Round(time.Millisecond))
+
+	return exitOK
 }
 
-func renderShadowGlamour() {
-	world := internal.NewWorld()
-
-	camera := internal.NewCamera(1920, 1080, 0.7854)
-	camera.Transform = internal.ViewTransform(internal.NewPoint(-3, 1, 2.5), internal.NewPoint(0, 0.5, 0), internal.NewVector(0, 1, 0))
-
-	light := internal.NewAreaLight(
-		internal.NewPoint(-1, 2, 4),
-		internal.NewVector(2, 0, 0),
-		10,
-		internal.NewVector(0, 2, 0),
-		10,
-		internal.NewColor(1.5, 1.5, 1.5),
-	)
-	light.Jitter = true
-
-	cube := internal.NewCube()
-	cube.HasShadow = false
-	cubeMaterial := internal.NewDefaultMaterial()
-	cubeMaterial.SetColor(internal.NewColor(1.5, 1.5, 1.5))
-	cubeMaterial.Ambient = 1.0
-	cubeMaterial.Diffuse = 0.0
-	cubeMaterial.Specular = 0.0
-	cube.SetMaterial(cubeMaterial)
-	cube.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0, 3, 4),
-			internal.Scale(1, 1, 0.01),
-		),
-	)
-
-	plane := internal.NewPlane()
-	planeMaterial := internal.NewDefaultMaterial()
-	planeMaterial.SetColor(internal.NewColor(1, 1, 1))
-	planeMaterial.Ambient = 0.025
-	planeMaterial.Diffuse = 0.67
-	planeMaterial.Specular = 0.0
-	plane.SetMaterial(planeMaterial)
-
-	sphere1 := internal.NewSphere()
-	sphereMaterial1 := internal.NewDefaultMaterial()
-	sphereMaterial1.SetColor(internal.NewColor(1, 0, 0))
-	sphereMaterial1.Ambient = 0.1
-	sphereMaterial1.Diffuse = 0.6
-	sphereMaterial1.Reflective = 0.3
-	sphereMaterial1.Specular = 0.0
-	sphere1.SetMaterial(sphereMaterial1)
-	sphere1.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0.5, 0.5, 0),
-			internal.Scale(0.5, 0.5, 0.5),
-		),
-	)
-
-	sphere2 := internal.NewSphere()
-	sphereMaterial2 := internal.NewDefaultMaterial()
-	sphereMaterial2.SetColor(internal.NewColor(0.5, 0.5, 1))
-	sphereMaterial2.Ambient = 0.1
-	sphereMaterial2.Diffuse = 0.6
-	sphereMaterial2.Reflective = 0.3
-	sphereMaterial2.Specular = 0.0
-	sphere2.SetMaterial(sphereMaterial2)
-	sphere2.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-0.25, 0.33, 0),
-			internal.Scale(0.33, 0.33, 0.33),
-		),
-	)
-
-	world.Lights = append(world.Lights, light)
-	world.Objects = append(world.Objects, cube, plane, sphere1, sphere2)
-
-	canvas := internal.Render(camera, world)
-	writeToPng(canvas, "shadow_glamour.png")
+func sceneCameras(camera internal.Camera, world internal.World, s renderSettings, stderr io.Writer) ([]internal.Camera, int, bool) {
+	f := s.flags
+
+	if s.hasProjection {
+		camera.Projection = s.projection
+	}
+
+	if f.viewWidth > 0 {
+		camera.ViewWidth = f.viewWidth
+	}
+
+	if camera.Projection == internal.ProjectionOrthographic && camera.ViewWidth <= 0 {
+		fmt.Fprintln(stderr, "gotracer render: orthographic projection needs a positive -view-width")
+		return nil, exitUsage, false
+	}
+
+	camera.SetSize(camera.Hsize, camera.Vsize, camera.FOV)
+	camera = overrideCamera(camera, f.width, f.height, f.fov)
+
+	if f.aperture != unsetFlag {
+		camera.Aperture = f.aperture
+	}
+	if f.focalDistance > 0 {
+		camera.FocalDistance = f.focalDistance
+	}
+	if f.blades > 0 {
+		camera.Blades = f.blades
+	}
+	if f.shutterOpen != unsetFlag {
+		camera.ShutterOpen = f.shutterOpen
+	}
+	if f.shutterClose != unsetFlag {
+		camera.ShutterClose = f.shutterClose
+	}
+
+	if camera.ShutterOpen > camera.ShutterClose || camera.ShutterClose > 1 {
+		fmt.Fprintln(stderr, "gotracer render: shutter must satisfy 0 <= shutter-open <= shutter-close <= 1")
+		return nil, exitUsage, false
+	}
+
+	if f.autofocus {
+		distance, ok := internal.AutoFocus(camera, world, camera.Hsize/2, camera.Vsize/2)
+
+		if !ok {
+			fmt.Fprintln(stderr, "gotracer render: autofocus found no object at the image centre")
+			return nil, exitFailure, false
+		}
+
+		camera.FocalDistance = distance
+	}
+
+	camera.Samples = f.samples
+	camera.FilterRadius = f.filterRadius
+	camera.Sampling = s.sampling
+	camera.Filter = s.filter
+
+	if f.stereo == "" {
+		return []internal.Camera{camera}, exitOK, true
+	}
+
+	distance := f.convergence
+
+	if distance == 0 {
+		distance = camera.FocalDistance
+	}
+
+	if distance == 0 {
+		var ok bool
+
+		if distance, ok = internal.AutoFocus(camera, world, camera.Hsize/2, camera.Vsize/2); !ok {
+			fmt.Fprintln(stderr, "gotracer render: stereo found no object at the image centre to converge on, set -convergence")
+			return nil, exitFailure, false
+		}
+	}
+
+	left, right := internal.NewStereoRig(camera, f.interocular, distance).Eyes()
+
+	return []internal.Camera{left, right}, exitOK, true
 }
 
-func renderReflectionRefraction() {
-	world := internal.NewWorld()
-
-	camera := internal.NewCamera(1920, 1080, 1.152)
-	camera.Transform = internal.ViewTransform(
-		internal.NewPoint(-2.6, 1.5, -3.9),
-		internal.NewPoint(-0.6, 1, -0.8),
-		internal.NewPoint(0, 1, 0),
-	)
-
-	light := internal.NewPointLight(internal.NewPoint(-4.9, 4.9, -1), internal.NewColor(1, 1, 1))
-
-	wallMaterial := internal.NewDefaultMaterial()
-	wallPattern := internal.NewStripePattern(
-		internal.NewColor(0.45, 0.45, 0.45),
-		internal.NewColor(0.55, 0.55, 0.55),
-	)
-	wallPattern.SetTransform(
-		internal.MatrixMultiply(
-			internal.RotateY(1.5708),
-			internal.Scale(0.25, 0.25, 0.25),
-		),
-	)
-	wallMaterial.Ambient = 0.0
-	wallMaterial.Diffuse = 0.4
-	wallMaterial.Specular = 0.0
-	wallMaterial.Reflective = 0.3
-	wallMaterial.SetPattern(wallPattern)
-
-	floor := internal.NewPlane()
-	floor.SetTransform(internal.RotateY(0.31415))
-	floorMaterial := internal.NewDefaultMaterial()
-	floorPattern := internal.NewCheckersPattern(
-		internal.NewColor(0.35, 0.35, 0.35),
-		internal.NewColor(0.65, 0.65, 0.65),
-	)
-	floorMaterial.SetPattern(floorPattern)
-	floorMaterial.Specular = 0.0
-	floorMaterial.Reflective = 0.4
-	floor.SetMaterial(floorMaterial)
-
-	ceiling := internal.NewPlane()
-	ceiling.SetTransform(internal.Translate(0, 5, 0))
-	ceilingMaterial := internal.NewDefaultMaterial()
-	ceilingMaterial.Ambient = 0.3
-	ceilingMaterial.Specular = 0.0
-	ceilingMaterial.SetColor(internal.NewColor(0.8, 0.8, 0.8))
-	ceiling.SetMaterial(ceilingMaterial)
-
-	westWall := internal.NewPlane()
-	westWall.SetTransform(
-		internal.MatrixMultiply(
-			internal.MatrixMultiply(
-				internal.Translate(-5, 0, 0),
-				internal.RotateZ(1.5708),
-			),
-			internal.RotateY(1.5708),
-		),
-	)
-	westWall.SetMaterial(wallMaterial)
-
-	eastWall := internal.NewPlane()
-	eastWall.SetTransform(
-		internal.MatrixMultiply(
-			internal.MatrixMultiply(
-				internal.Translate(5, 0, 0),
-				internal.RotateZ(1.5708),
-			),
-			internal.RotateY(1.5708),
-		),
-	)
-	eastWall.SetMaterial(wallMaterial)
-
-	northWall := internal.NewPlane()
-	northWall.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0, 0, 5),
-			internal.RotateX(1.5708),
-		),
-	)
-	northWall.SetMaterial(wallMaterial)
-
-	southWall := internal.NewPlane()
-	southWall.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0, 0, -5),
-			internal.RotateX(1.5708),
-		),
-	)
-	southWall.SetMaterial(wallMaterial)
-
-	ball1 := internal.NewSphere()
-	ball1.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(4.6, 0.4, 1),
-			internal.Scale(0.4, 0.4, 0.4),
-		),
-	)
-	ball1Material := internal.NewDefaultMaterial()
-	ball1Material.SetColor(internal.NewColor(0.8, 0.5, 0.3))
-	ball1Material.Shininess = 50
-	ball1.SetMaterial(ball1Material)
-
-	ball2 := internal.NewSphere()
-	ball2.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(4.7, 0.3, 0.4),
-			internal.Scale(0.3, 0.3, 0.3),
-		),
-	)
-	ball2Material := internal.NewDefaultMaterial()
-	ball2Material.SetColor(internal.NewColor(0.9, 0.4, 0.5))
-	ball2Material.Shininess = 50
-	ball2.SetMaterial(ball2Material)
-
-	ball3 := internal.NewSphere()
-	ball3.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-1, 0.5, 4.5),
-			internal.Scale(0.5, 0.5, 0.5),
-		),
-	)
-	ball3Material := internal.NewDefaultMaterial()
-	ball3Material.SetColor(internal.NewColor(0.4, 0.9, 0.6))
-	ball3Material.Shininess = 50
-	ball3.SetMaterial(ball3Material)
-
-	ball4 := internal.NewSphere()
-	ball4.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-1.7, 0.3, 4.7),
-			internal.Scale(0.3, 0.3, 0.3),
-		),
-	)
-	ball4Material := internal.NewDefaultMaterial()
-	ball4Material.SetColor(internal.NewColor(0.4, 0.6, 0.9))
-	ball4Material.Shininess = 50
-	ball4.SetMaterial(ball4Material)
-
-	ball5 := internal.NewSphere()
-	ball5.SetTransform(
-		internal.Translate(-0.6, 1, 0.6),
-	)
-	ball5Material := internal.NewDefaultMaterial()
-	ball5Material.SetColor(internal.NewColor(1, 0.3, 0.2))
-	ball5Material.Specular = 0.4
-	ball5Material.Shininess = 5
-	ball5.SetMaterial(ball5Material)
-
-	ball6 := internal.NewSphere()
-	ball6.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0.6, 0.7, -0.6),
-			internal.Scale(0.7, 0.7, 0.7),
-		),
-	)
-	ball6Material := internal.NewDefaultMaterial()
-	ball6Material.SetColor(internal.NewColor(0, 0, 0.2))
-	ball6Material.Ambient = 0.0
-	ball6Material.Diffuse = 0.4
-	ball6Material.Specular = 0.9
-	ball6Material.Shininess = 300
-	ball6Material.Reflective = 0.9
-	ball6Material.Transparency = 0.9
-	ball6Material.RefractiveIndex = 1.5
-	ball6.SetMaterial(ball6Material)
-
-	ball7 := internal.NewSphere()
-	ball7.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-0.7, 0.5, -0.8),
-			internal.Scale(0.5, 0.5, 0.5),
-		),
-	)
-	ball7Material := internal.Material{}
-	ball7Material.SetColor(internal.NewColor(0, 0.2, 0))
-	ball7Material.Ambient = 0.0
-	ball7Material.Diffuse = 0.4
-	ball7Material.Specular = 0.9
-	ball7Material.Shininess = 300
-	ball7Material.Reflective = 0.9
-	ball7Material.Transparency = 0.9
-	ball7Material.RefractiveIndex = 1.5
-	ball7.SetMaterial(ball7Material)
-
-	objects := []internal.Shape{
-		floor,
-		ceiling,
-		northWall,
-		southWall,
-		eastWall,
-		westWall,
-		ball1,
-		ball2,
-		ball3,
-		ball4,
-		ball5,
-		ball6,
-		ball7,
-	}
-	world.Lights = append(world.Lights, light)
-	world.Objects = append(world.Objects, objects...)
-
-	canvas := internal.Render(camera, world)
-	writeToPng(canvas, "reflection_refraction.png")
+func renderViews(ctx context.Context, cameras []internal.Camera, world internal.World, s renderSettings, outputPath string, stderr io.Writer) ([]renderOutput, int, time.Duration, error) {
+	var printProgress func(internal.RenderProgress)
+	var final internal.RenderProgress
+
+	if s.flags.progress {
+		printProgress = progressPrinter(stderr)
+	}
+
+	opts := s.opts
+	opts.Progress = func(p internal.RenderProgress) {
+		final = p
+
+		if printProgress != nil {
+			printProgress(p)
+		}
+	}
+
+	var outputs []renderOutput
+	var totalSamples int
+	var elapsed time.Duration
+
+	for _, view := range cameras {
+		canvas, passes, err := internal.RenderPasses(ctx, view, world, opts)
+
+		if s.flags.progress {
+			fmt.Fprintln(stderr)
+		}
+
+		totalSamples += final.Samples
+		elapsed += final.Elapsed
+		outputs = append(outputs, renderOutput{canvas, passes, outputPath})
+
+		if err != nil {
+			return outputs, totalSamples, elapsed, err
+		}
+	}
+
+	return outputs, totalSamples, elapsed, nil
 }
 
-func renderTable() {
-	world := internal.NewWorld()
-
-	camera := internal.NewCamera(1920, 1080, 0.785)
-	camera.Transform = internal.ViewTransform(internal.NewPoint(8, 6, -8), internal.NewPoint(0, 3, 0), internal.NewVector(0, 1, 0))
-
-	light := internal.NewPointLight(internal.NewPoint(0, 6.9, -5), internal.NewColor(1, 1, 0.9))
-
-	floor := internal.NewCube()
-	floorMaterial := internal.NewDefaultMaterial()
-	floorPattern := internal.NewCheckersPattern(
-		internal.NewColor(0, 0, 0),
-		internal.NewColor(0.25, 0.25, 0.25),
-	)
-	floorPattern.SetTransform(internal.Scale(0.07, 0.07, 0.07))
-	floorMaterial.Ambient = 0.25
-	floorMaterial.Diffuse = 0.7
-	floorMaterial.Specular = 0.9
-	floorMaterial.Shininess = 300
-	floorMaterial.Reflective = 0.1
-	floorMaterial.SetPattern(floorPattern)
-	floor.SetMaterial(floorMaterial)
-	floor.SetTransform(
-		internal.MatrixMultiply(
-			internal.Scale(20, 7, 20),
-			internal.Translate(0, 1, 0),
-		),
-	)
-
-	wall := internal.NewCube()
-	wallMaterial := internal.NewDefaultMaterial()
-	wallPattern := internal.NewCheckersPattern(
-		internal.NewColor(0.4863, 0.3765, 0.2941),
-		internal.NewColor(0.3725, 0.2902, 0.2275),
-	)
-	wallPattern.SetTransform(internal.Scale(0.05, 20, 0.05))
-	wallMaterial.Ambient = 0.1
-	wallMaterial.Diffuse = 0.7
-	wallMaterial.Specular = 0.9
-	wallMaterial.Shininess = 300
-	wallMaterial.Reflective = 0.1
-	wallMaterial.SetPattern(wallPattern)
-	wall.SetMaterial(wallMaterial)
-	wall.SetTransform(internal.Scale(10, 10, 10))
-
-	tableTop := internal.NewCube()
-	tableMaterial := internal.NewDefaultMaterial()
-	tablePattern := internal.NewStripePattern(
-		internal.NewColor(0.5529, 0.4235, 0.3255),
-		internal.NewColor(0.6588, 0.5098, 0.4000),
-	)
-	tablePattern.SetTransform(
-		internal.MatrixMultiply(
-			internal.Scale(0.05, 0.05, 0.05),
-			internal.RotateY(0.1),
-		),
-	)
-	tableMaterial.Ambient = 0.1
-	tableMaterial.Diffuse = 0.7
-	tableMaterial.Specular = 0.9
-	tableMaterial.Shininess = 300
-	tableMaterial.Reflective = 0.2
-	tableMaterial.SetPattern(tablePattern)
-	tableTop.SetMaterial(tableMaterial)
-	tableTop.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0, 3.1, 0),
-			internal.Scale(3, 0.1, 2),
-		),
-	)
-
-	legMaterial := internal.NewDefaultMaterial()
-	legMaterial.SetColor(internal.NewColor(0.5529, 0.4235, 0.3255))
-	legMaterial.Ambient = 0.2
-	legMaterial.Diffuse = 0.7
-
-	leg1 := internal.NewCube()
-	leg1.SetMaterial(legMaterial)
-	leg1.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(2.7, 1.5, -1.7),
-			internal.Scale(0.1, 1.5, 0.1),
-		),
-	)
-
-	leg2 := internal.NewCube()
-	leg2.SetMaterial(legMaterial)
-	leg2.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(2.7, 1.5, 1.7),
-			internal.Scale(0.1, 1.5, 0.1),
-		),
-	)
-
-	leg3 := internal.NewCube()
-	leg3.SetMaterial(legMaterial)
-	leg3.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-2.7, 1.5, -1.7),
-			internal.Scale(0.1, 1.5, 0.1),
-		),
-	)
-
-	leg4 := internal.NewCube()
-	leg4.SetMaterial(legMaterial)
-	leg4.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-2.7, 1.5, 1.7),
-			internal.Scale(0.1, 1.5, 0.1),
-		),
-	)
-
-	glassCube := internal.NewCube()
-	glassCube.HasShadow = false
-	glassCube.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0, 3.45001, 0),
-			internal.MatrixMultiply(
-				internal.RotateY(0.2),
-				internal.Scale(0.25, 0.25, 0.25),
-			),
-		),
-	)
-	glassCubeMaterial := internal.NewDefaultMaterial()
-	glassCubeMaterial.SetColor(internal.NewColor(1, 1, 0.8))
-	glassCubeMaterial.Ambient = 0.0
-	glassCubeMaterial.Diffuse = 0.3
-	glassCubeMaterial.Specular = 0.9
-	glassCubeMaterial.Shininess = 300
-	glassCubeMaterial.Reflective = 0.7
-	glassCubeMaterial.Transparency = 0.7
-	glassCubeMaterial.RefractiveIndex = 1.5
-	glassCube.SetMaterial(glassCubeMaterial)
-
-	cube1 := internal.NewCube()
-	cube1.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(1, 3.35, -0.9),
-			internal.MatrixMultiply(
-				internal.RotateY(-0.4),
-				internal.Scale(0.15, 0.15, 0.15),
-			),
-		),
-	)
-	cube1Material := internal.NewDefaultMaterial()
-	cube1Material.SetColor(internal.NewColor(1, 0.5, 0.5))
-	cube1Material.Reflective = 0.6
-	cube1Material.Diffuse = 0.4
-	cube1.SetMaterial(cube1Material)
-
-	cube2 := internal.NewCube()
-	cube2.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-1.5, 3.27, 0.3),
-			internal.MatrixMultiply(
-				internal.RotateY(0.4),
-				internal.Scale(0.15, 0.07, 0.15),
-			),
-		),
-	)
-	cube2Material := internal.NewDefaultMaterial()
-	cube2Material.SetColor(internal.NewColor(1, 1, 0.5))
-	cube2.SetMaterial(cube2Material)
-
-	cube3 := internal.NewCube()
-	cube3.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0, 3.25, 1),
-			internal.MatrixMultiply(
-				internal.RotateY(0.4),
-				internal.Scale(0.2, 0.05, 0.05),
-			),
-		),
-	)
-	cube3Material := internal.NewDefaultMaterial()
-	cube3Material.SetColor(internal.NewColor(0.5, 1, 0.5))
-	cube3.SetMaterial(cube3Material)
-
-	cube4 := internal.NewCube()
-	cube4.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-0.6, 3.4, -1),
-			internal.MatrixMultiply(
-				internal.RotateY(0.8),
-				internal.Scale(0.05, 0.2, 0.05),
-			),
-		),
-	)
-	cube4Material := internal.NewDefaultMaterial()
-	cube4Material.SetColor(internal.NewColor(0.5, 0.5, 1))
-	cube4.SetMaterial(cube4Material)
-
-	cube5 := internal.NewCube()
-	cube5.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(2, 3.4, 1),
-			internal.MatrixMultiply(
-				internal.RotateY(0.8),
-				internal.Scale(0.05, 0.2, 0.05),
-			),
-		),
-	)
-	cube5Material := internal.NewDefaultMaterial()
-	cube5Material.SetColor(internal.NewColor(0.5, 1, 1))
-	cube5.SetMaterial(cube5Material)
-
-	frame1 := internal.NewCube()
-	frame1.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-10, 4, 1),
-			internal.Scale(0.05, 1, 1),
-		),
-	)
-	frame1Material := internal.NewDefaultMaterial()
-	frame1Material.SetColor(internal.NewColor(0.7098, 0.2471, 0.2196))
-	frame1Material.Diffuse = 0.6
-	frame1.SetMaterial(frame1Material)
-
-	frame2 := internal.NewCube()
-	frame2.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-10, 3.4, 2.7),
-			internal.Scale(0.05, 0.4, 0.4),
-		),
-	)
-	frame2Material := internal.NewDefaultMaterial()
-	frame2Material.SetColor(internal.NewColor(0.2667, 0.2706, 0.6902))
-	frame2Material.Diffuse = 0.6
-	frame2.SetMaterial(frame2Material)
-
-	frame3 := internal.NewCube()
-	frame3.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-10, 4.6, 2.7),
-			internal.Scale(0.05, 0.4, 0.4),
-		),
-	)
-	frame3Material := internal.NewDefaultMaterial()
-	frame3Material.SetColor(internal.NewColor(0.3098, 0.5961, 0.3098))
-	frame3Material.Diffuse = 0.6
-	frame3.SetMaterial(frame3Material)
-
-	frame4 := internal.NewCube()
-	frame4.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-2, 3.5, 9.95),
-			internal.Scale(5, 1.5, 0.05),
-		),
-	)
-	frame4Material := internal.NewDefaultMaterial()
-	frame4Material.SetColor(internal.NewColor(0.3882, 0.2627, 0.1882))
-	frame4Material.Diffuse = 0.7
-	frame4.SetMaterial(frame4Material)
-
-	mirror := internal.NewCube()
-	mirror.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-2, 3.5, 9.95),
-			internal.Scale(4.8, 1.4, 0.06),
-		),
-	)
-	mirrorMaterial := internal.NewDefaultMaterial()
-	mirrorMaterial.SetColor(internal.NewColor(0, 0, 0))
-	mirrorMaterial.Diffuse = 0.0
-	mirrorMaterial.Ambient = 0.0
-	mirrorMaterial.Specular = 1.0
-	mirrorMaterial.Shininess = 300
-	mirrorMaterial.Reflective = 1.0
-	mirror.SetMaterial(mirrorMaterial)
-
-	objects := []internal.Shape{
-		floor,
-		wall,
-		tableTop,
-		leg1,
-		leg2,
-		leg3,
-		leg4,
-		frame1,
-		frame2,
-		frame3,
-		frame4,
-		glassCube,
-		cube1,
-		cube2,
-		cube3,
-		cube4,
-		cube5,
-		mirror,
-	}
-	world.Lights = append(world.Lights, light)
-	world.Objects = append(world.Objects, objects...)
-
-	canvas := internal.Render(camera, world)
-	writeToPng(canvas, "table.png")
+func aovNames() string {
+	var names []string
+
+	for _, a := range internal.AllAOVs() {
+		names = append(names, a.String())
+	}
+
+	return strings.Join(names, ", ")
 }
 
-func renderCylinders() {
-	world := internal.NewWorld()
-
-	camera := internal.NewCamera(1920, 1080, 0.314)
-	camera.Transform = internal.ViewTransform(internal.NewPoint(8, 3.5, -9), internal.NewPoint(0, 0.3, 0), internal.NewPoint(0, 1, 0))
-
-	light := internal.NewPointLight(internal.NewPoint(1, 6.9, -4.9), internal.NewColor(1, 1, 1))
-
-	floor := internal.NewPlane()
-	floorMaterial := internal.NewDefaultMaterial()
-	floorPattern := internal.NewCheckersPattern(
-		internal.NewColor(0.5, 0.5, 0.5),
-		internal.NewColor(0.75, 0.75, 0.75),
-	)
-	floorPattern.SetTransform(
-		internal.MatrixMultiply(
-			internal.RotateY(0.3),
-			internal.Scale(0.25, 0.25, 0.25),
-		),
-	)
-	floorMaterial.SetPattern(floorPattern)
-	floorMaterial.Ambient = 0.2
-	floorMaterial.Diffuse = 0.9
-	floorMaterial.Specular = 0.0
-	floor.SetMaterial(floorMaterial)
-
-	cylinder1 := internal.NewCylinder()
-	cylinder1.Minimum = 0
-	cylinder1.Maximum = 0.75
-	cylinder1.Closed = true
-	cylinder1.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(-1, 0, 1),
-			internal.Scale(0.5, 1, 0.5),
-		),
-	)
-	cylinder1Material := internal.NewDefaultMaterial()
-	cylinder1Material.SetColor(internal.NewColor(0, 0, 0.6))
-	cylinder1Material.Diffuse = 0.1
-	cylinder1Material.Specular = 0.9
-	cylinder1Material.Shininess = 300
-	cylinder1Material.Reflective = 0.9
-	cylinder1.SetMaterial(cylinder1Material)
-
-	cylinder2 := internal.NewCylinder()
-	cylinder2.Minimum = 0
-	cylinder2.Maximum = 0.2
-	cylinder2.Closed = false
-	cylinder2.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(1, 0, 0),
-			internal.Scale(0.8, 1, 0.8),
-		),
-	)
-	cylinder2Material := internal.NewDefaultMaterial()
-	cylinder2Material.SetColor(internal.NewColor(1, 1, 0.3))
-	cylinder2Material.Ambient = 0.1
-	cylinder2Material.Diffuse = 0.8
-	cylinder2Material.Specular = 0.9
-	cylinder2Material.Shininess = 300
-	cylinder2.SetMaterial(cylinder2Material)
-
-	cylinder3 := internal.NewCylinder()
-	cylinder3.Minimum = 0
-	cylinder3.Maximum = 0.3
-	cylinder3.Closed = false
-	cylinder3.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(1, 0, 0),
-			internal.Scale(0.6, 1, 0.6),
-		),
-	)
-	cylinder3Material := internal.NewDefaultMaterial()
-	cylinder3Material.SetColor(internal.NewColor(1, 0.9, 0.4))
-	cylinder3Material.Ambient = 0.1
-	cylinder3Material.Diffuse = 0.8
-	cylinder3Material.Specular = 0.9
-	cylinder3Material.Shininess = 300
-	cylinder3.SetMaterial(cylinder3Material)
-
-	cylinder4 := internal.NewCylinder()
-	cylinder4.Minimum = 0
-	cylinder4.Maximum = 0.4
-	cylinder4.Closed = false
-	cylinder4.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(1, 0, 0),
-			internal.Scale(0.4, 1, 0.4),
-		),
-	)
-	cylinder4Material := internal.NewDefaultMaterial()
-	cylinder4Material.SetColor(internal.NewColor(1, 0.8, 0.5))
-	cylinder4Material.Ambient = 0.1
-	cylinder4Material.Diffuse = 0.8
-	cylinder4Material.Specular = 0.9
-	cylinder4Material.Shininess = 300
-	cylinder4.SetMaterial(cylinder4Material)
-
-	cylinder5 := internal.NewCylinder()
-	cylinder5.Minimum = 0
-	cylinder5.Maximum = 0.5
-	cylinder5.Closed = true
-	cylinder5.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(1, 0, 0),
-			internal.Scale(0.2, 1, 0.2),
-		),
-	)
-	cylinder5Material := internal.NewDefaultMaterial()
-	cylinder5Material.SetColor(internal.NewColor(1, 0.7, 0.6))
-	cylinder5Material.Ambient = 0.1
-	cylinder5Material.Diffuse = 0.8
-	cylinder5Material.Specular = 0.9
-	cylinder5Material.Shininess = 300
-	cylinder5.SetMaterial(cylinder5Material)
-
-	cylinder6 := internal.NewCylinder()
-	cylinder6.Minimum = 0
-	cylinder6.Maximum = 0.3
-	cylinder6.Closed = true
-	cylinder6.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0, 0, -0.75),
-			internal.Scale(0.05, 1, 0.05),
-		),
-	)
-	cylinder6Material := internal.NewDefaultMaterial()
-	cylinder6Material.SetColor(internal.NewColor(1, 0, 0))
-	cylinder6Material.Ambient = 0.1
-	cylinder6Material.Diffuse = 0.9
-	cylinder6Material.Specular = 0.9
-	cylinder6Material.Shininess = 300
-	cylinder6.SetMaterial(cylinder6Material)
-
-	cylinder7 := internal.NewCylinder()
-	cylinder7.Minimum = 0
-	cylinder7.Maximum = 0.3
-	cylinder7.Closed = true
-	cylinder7.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0, 0, -2.25),
-			internal.MatrixMultiply(
-				internal.RotateY(-0.15),
-				internal.MatrixMultiply(
-					internal.Translate(0, 0, 1.5),
-					internal.Scale(0.05, 1, 0.05),
-				),
-			),
-		),
-	)
-	cylinder7Material := internal.NewDefaultMaterial()
-	cylinder7Material.SetColor(internal.NewColor(1, 1, 0))
-	cylinder7Material.Ambient = 0.1
-	cylinder7Material.Diffuse = 0.9
-	cylinder7Material.Specular = 0.9
-	cylinder7Material.Shininess = 300
-	cylinder7.SetMaterial(cylinder7Material)
-
-	cylinder8 := internal.NewCylinder()
-	cylinder8.Minimum = 0
-	cylinder8.Maximum = 0.3
-	cylinder8.Closed = true
-	cylinder8.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0, 0, -2.25),
-			internal.MatrixMultiply(
-				internal.RotateY(-0.3),
-				internal.MatrixMultiply(
-					internal.Translate(0, 0, 1.5),
-					internal.Scale(0.05, 1, 0.05),
-				),
-			),
-		),
-	)
-	cylinder8Material := internal.NewDefaultMaterial()
-	cylinder8Material.SetColor(internal.NewColor(0, 1, 0))
-	cylinder8Material.Ambient = 0.1
-	cylinder8Material.Diffuse = 0.9
-	cylinder8Material.Specular = 0.9
-	cylinder8Material.Shininess = 300
-	cylinder8.SetMaterial(cylinder8Material)
-
-	cylinder9 := internal.NewCylinder()
-	cylinder9.Minimum = 0
-	cylinder9.Maximum = 0.3
-	cylinder9.Closed = true
-	cylinder9.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0, 0, -2.25),
-			internal.MatrixMultiply(
-				internal.RotateY(-0.45),
-				internal.MatrixMultiply(
-					internal.Translate(0, 0, 1.5),
-					internal.Scale(0.05, 1, 0.05),
-				),
-			),
-		),
-	)
-	cylinder9Material := internal.NewDefaultMaterial()
-	cylinder9Material.SetColor(internal.NewColor(0, 1, 1))
-	cylinder9Material.Ambient = 0.1
-	cylinder9Material.Diffuse = 0.9
-	cylinder9Material.Specular = 0.9
-	cylinder9Material.Shininess = 300
-	cylinder9.SetMaterial(cylinder9Material)
-
-	glassCylinder := internal.NewCylinder()
-	glassCylinder.Minimum = 0.0001
-	glassCylinder.Maximum = 0.5
-	glassCylinder.Closed = true
-	glassCylinder.SetTransform(
-		internal.MatrixMultiply(
-			internal.Translate(0, 0, -1.5),
-			internal.Scale(0.33, 1, 0.33),
-		),
-	)
-	glassCylinderMaterial := internal.NewDefaultMaterial()
-	glassCylinderMaterial.SetColor(internal.NewColor(0.25, 0, 0))
-	glassCylinderMaterial.Diffuse = 0.1
-	glassCylinderMaterial.Specular = 0.9
-	glassCylinderMaterial.Shininess = 300
-	glassCylinderMaterial.Reflective = 0.9
-	glassCylinderMaterial.Transparency = 0.9
-	glassCylinderMaterial.RefractiveIndex = 1.5
-	glassCylinder.SetMaterial(glassCylinderMaterial)
-
-	objects := []internal.Shape{
-		floor,
-		cylinder1,
-		cylinder2,
-		cylinder3,
-		cylinder4,
-		cylinder5,
-		cylinder6,
-		cylinder7,
-		cylinder8,
-		cylinder9,
-		glassCylinder,
-	}
-	world.Lights = append(world.Lights, light)
-	world.Objects = append(world.Objects, objects...)
-
-	canvas := internal.Render(camera, world)
-	writeToPng(canvas, "cylinders.png")
+func hasLightingAOV(aovs []internal.AOV) bool {
+	for _, a := range aovs {
+		if a.Lighting() {
+			return true
+		}
+	}
+
+	return false
 }
 
-func main() {
-	// renderCircle()
-	// renderSphere()
-	// renderScene()
-	// renderRefraction()
-	// renderReflectionRefraction()
-	// renderTable()
-	// renderCylinders()
-	renderShadowGlamour()
+func stereoLayoutNames() string {
+	var names []string
+
+	for _, l := range internal.StereoLayouts() {
+		names = append(names, l.String())
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func projectionNames() string {
+	var names []string
+
+	for _, p := range internal.Projections() {
+		names = append(names, p.String())
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func parseAOVs(list string) ([]internal.AOV, error) {
+	if list == "" {
+		return nil, nil
+	}
+
+	if list == "all" {
+		return internal.AllAOVs(), nil
+	}
+
+	var aovs []internal.AOV
+
+	for _, name := range strings.Split(list, ",") {
+		a, err := internal.ParseAOV(strings.TrimSpace(name))
+
+		if err != nil {
+			return nil, err
+		}
+
+		aovs = append(aovs, a)
+	}
+
+	return aovs, nil
+}
+
+type renderOutput struct {
+	canvas *internal.Canvas
+	passes internal.Passes
+	path   string
+}
+
+func stereoOutputs(left, right renderOutput, layout internal.StereoLayout) ([]renderOutput, error) {
+	if layout == internal.StereoSeparate {
+		left.path = passPath(left.path, internal.EyeLeft.String())
+		right.path = passPath(right.path, internal.EyeRight.String())
+
+		return []renderOutput{left, right}, nil
+	}
+
+	canvas, err := internal.CombineStereo(left.canvas, right.canvas, layout)
+
+	if err != nil {
+		return nil, err
+	}
+
+	passes := make(internal.Passes, len(left.passes))
+
+	for name, pass := range left.passes {
+		if passes[name], err = internal.CombineStereo(pass, right.passes[name], layout); err != nil {
+			return nil, err
+		}
+	}
+
+	return []renderOutput{{canvas, passes, left.path}}, nil
+}
+
+func passPath(outputPath, name string) string {
+	ext := filepath.Ext(outputPath)
+	return strings.TrimSuffix(outputPath, ext) + "." + name + ext
+}
+
+func writePasses(passes internal.Passes, outputPath string) error {
+	for name, pass := range passes {
+		if name == internal.AOVDepth.String() {
+			pass = internal.NormalizePass(pass)
+		}
+
+		if err := writeImage(pass, passPath(outputPath, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func renderContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+
+	go func() {
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+
+		signal.Stop(interrupt)
+	}()
+
+	return ctx, cancel
+}
+
+func progressPrinter(w io.Writer) func(internal.RenderProgress) {
+	return func(p internal.RenderProgress) {
+		fmt.Fprintf(w, "\r%5.1f%%  tiles %d/%d  samples %d  elapsed %s  eta %s   ",
+			100*p.Fraction(),
+			p.TilesDone,
+			p.TilesTotal,
+			p.Samples,
+			p.Elapsed.Round(time.Second),
+			p.ETA.Round(time.Second),
+		)
+	}
+}
+
+func runScenes(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("scenes", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 0 {
+		fmt.Fprintln(stderr, "gotracer scenes: unexpected arguments")
+		return exitUsage
+	}
+
+	for _, scene := range builtinScenes {
+		fmt.Fprintf(stdout, "%-24s %s\n", scene.Name, scene.Description)
+	}
+
+	return exitOK
+}
+
+func runInfo(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("info", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	sceneRef := fs.String("scene", "", "scene file (.yml/.yaml) or built-in scene name")
+
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	ref, ok := sceneArgument(fs, *sceneRef, stderr)
+
+	if !ok {
+		return exitUsage
+	}
+
+	world, camera, err := loadScene(ref)
+
+	if err != nil {
+		fmt.Fprintf(stderr, "gotracer info: %v\n", err)
+		return exitFailure
+	}
+
+	fmt.Fprintf(stdout, "scene:   %s\n", ref)
+	fmt.Fprintf(stdout, "camera:  %dx%d, fov %.4f rad\n", camera.Hsize, camera.Vsize, camera.FOV)
+	fmt.Fprintf(stdout, "lights:  %d\n", len(world.Lights))
+
+	for _, light := range world.Lights {
+		switch l := light.(type) {
+		case internal.PointLight:
+			fmt.Fprintf(stdout, "  point at (%g, %g, %g)\n", l.Position.X, l.Position.Y, l.Position.Z)
+		case internal.AreaLight:
+			fmt.Fprintf(stdout, "  area at (%g, %g, %g), %d samples\n", l.Position.X, l.Position.Y, l.Position.Z, l.Samples)
+		default:
+			fmt.Fprintf(stdout, "  %T\n", l)
+		}
+	}
+
+	counts := make(map[string]int)
+
+	for _, obj := range world.Objects {
+		countShapes(obj, counts)
+	}
+
+	fmt.Fprintf(stdout, "objects: %d top-level\n", len(world.Objects))
+
+	var kinds []string
+
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		fmt.Fprintf(stdout, "  %-16s %d\n", kind, counts[kind])
+	}
+
+	return exitOK
+}
+
+func countShapes(shape internal.Shape, counts map[string]int) {
+	switch s := shape.(type) {
+	case *internal.Group:
+		counts["group"]++
+
+		for _, child := range s.Children {
+			countShapes(child, counts)
+		}
+	case *internal.CSG:
+		counts["csg"]++
+		countShapes(s.Left, counts)
+		countShapes(s.Right, counts)
+	default:
+		name := strings.ToLower(strings.TrimPrefix(fmt.Sprintf("%T", shape), "*internal."))
+		counts[name]++
+	}
+}
+
+func loadScene(ref string) (internal.World, internal.Camera, error) {
+	source, err := openScene(ref)
+
+	if err != nil {
+		return internal.World{}, internal.Camera{}, err
+	}
+
+	scene, err := source(0, 0)
+
+	if err != nil {
+		return internal.World{}, internal.Camera{}, err
+	}
+
+	return scene.World, scene.Camera, nil
+}
+
+type sceneSource func(sceneTime, frameDuration float64) (*parser.Scene, error)
+
+func openScene(ref string) (sceneSource, error) {
+	if scene, ok := findBuiltinScene(ref); ok {
+		return func(sceneTime, frameDuration float64) (*parser.Scene, error) {
+			world, camera := scene.Build()
+			world.AssignShapeIDs()
+			return &parser.Scene{World: world, Camera: camera}, nil
+		}, nil
+	}
+
+	if _, err := os.Stat(ref); err != nil {
+		return nil, fmt.Errorf("%q is neither a built-in scene nor a readable scene file", ref)
+	}
+
+	doc, err := parser.LoadDocument(ref)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.SceneAt, nil
+}
+
+func parseFrames(frames string) (int, int, error) {
+	if frames == "" {
+		return 0, 0, nil
+	}
+
+	bounds := strings.SplitN(frames, "-", 2)
+	first, err := strconv.Atoi(bounds[0])
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	last := first
+
+	if len(bounds) == 2 {
+		if last, err = strconv.Atoi(bounds[1]); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if first < 0 || last < first {
+		return 0, 0, fmt.Errorf("invalid frame range %q", frames)
+	}
+
+	return first, last, nil
+}
+
+func sceneBaseName(ref string) string {
+	base := filepath.Base(ref)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func overrideCamera(camera internal.Camera, width, height int, fov float64) internal.Camera {
+	if width == 0 && height == 0 && fov == 0 {
+		return camera
+	}
+
+	if width == 0 && height != 0 {
+		width = height * camera.Hsize / camera.Vsize
+	} else if height == 0 && width != 0 {
+		height = width * camera.Vsize / camera.Hsize
+	}
+
+	if width == 0 {
+		width, height = camera.Hsize, camera.Vsize
+	}
+
+	if fov == 0 {
+		fov = camera.FOV
+	}
+
+	camera.SetSize(width, height, fov)
+
+	return camera
+}
+
+func writeImage(canvas *internal.Canvas, path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return writeToPng(canvas, path)
+	case ".ppm":
+		return writeToPPM(canvas, path)
+	default:
+		return fmt.Errorf("unsupported output format %q, use .png or .ppm", filepath.Ext(path))
+	}
+}
+
+func writeToPPM(canvas *internal.Canvas, file string) error {
+	return ioutil.WriteFile(file, []byte(canvas.ToPPM()), 0644)
 }
 
 // Adapted from https://github.com/eriklupander/rt/blob/master/main.go
-func writeToPng(canvas *internal.Canvas, file string) {
+func writeToPng(canvas *internal.Canvas, file string) error {
 	image := image.NewRGBA(image.Rect(0, 0, canvas.W, canvas.H))
 	canvas.ToPNG(image)
-	outputFile, _ := os.Create(file)
-	defer outputFile.Close()
-	png.Encode(outputFile, image)
+	outputFile, err := os.Create(file)
+
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(outputFile, image); err != nil {
+		outputFile.Close()
+		return err
+	}
+
+	return outputFile.Close()
 }
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"gotracer/internal"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnknownCommandIsUsageError(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitUsage, run([]string{"paint"}, &stdout, &stderr))
+	assert.Equal(t, exitUsage, run(nil, &stdout, &stderr))
+}
+
+func TestScenesListsBuiltinScenes(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitOK, run([]string{"scenes"}, &stdout, &stderr))
+
+	for _, scene := range builtinScenes {
+		assert.True(t, strings.Contains(stdout.String(), scene.Name))
+	}
+}
+
+func TestRenderBuiltinScene(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	dir, _ := ioutil.TempDir("", "gotracer")
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "circle.ppm")
+
+	code := run([]string{"render", "-scene", "circle", "-width", "20", "-workers", "2", "-o", output}, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+	data, err := ioutil.ReadFile(output)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "P3\n20 20\n255\n"))
+}
+
+func TestRenderAdaptiveReportsSamples(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	dir, _ := ioutil.TempDir("", "gotracer")
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "sphere.png")
+
+	code := run([]string{"render", "-adaptive", "-width", "16", "-o", output, "sphere"}, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+	assert.True(t, strings.Contains(stdout.String(), "samples"))
+}
+
+func TestRenderSceneFile(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	dir, _ := ioutil.TempDir("", "gotracer")
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "example.png")
+
+	code := run([]string{"render", "-width", "32", "-o", output, "scenes/example.yml"}, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+	_, err := os.Stat(output)
+	assert.Nil(t, err)
+
+	code = run([]string{"render", "-transmissive-shadows", "-width", "16", "-o", output, "scenes/example.yml"}, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+}
+
+func TestRenderTimeoutWritesPartialImage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	dir, _ := ioutil.TempDir("", "gotracer")
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "partial.png")
+
+	code := run([]string{"render", "-timeout", "1ns", "-width", "64", "-o", output, "table"}, &stdout, &stderr)
+
+	assert.Equal(t, exitFailure, code)
+	_, err := os.Stat(output)
+	assert.Nil(t, err)
+}
+
+func TestRenderViewsStopAfterCancelledView(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	camera := internal.NewCamera(4, 4, 1)
+	s := renderSettings{flags: &renderFlags{}, opts: internal.DefaultRenderOptions()}
+
+	outputs, _, _, err := renderViews(ctx, []internal.Camera{camera, camera}, internal.NewWorld(), s, "stereo.png", ioutil.Discard)
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Len(t, outputs, 1)
+}
+
+func TestRenderWritesAOVPasses(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	dir, _ := ioutil.TempDir("", "gotracer")
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "sphere.png")
+
+	code := run([]string{"render", "-aov", "depth,normal,lights", "-width", "8", "-o", output, "sphere"}, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+
+	for _, name := range []string{"sphere.depth.png", "sphere.normal.png", "sphere.light-0.png"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.Nil(t, err, name)
+	}
+	assert.Empty(t, stderr.String())
+
+	code = run([]string{"render", "-integrator", "flat", "-aov", "direct", "-width", "8", "-o", output, "sphere"}, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stderr.String(), "Whitted")
+}
+
+func TestRenderStereoLayouts(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	dir, _ := ioutil.TempDir("", "gotracer")
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "sphere.png")
+
+	code := run([]string{"render", "-stereo", "separate", "-convergence", "5", "-width", "8", "-o", output, "sphere"}, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+
+	for _, name := range []string{"sphere.left.png", "sphere.right.png"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.Nil(t, err, name)
+	}
+
+	code = run([]string{"render", "-stereo", "side-by-side", "-width", "8", "-height", "8", "-o", output, "sphere"}, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout.String(), "(16x8,")
+}
+
+func TestRenderAnimationWritesNumberedFrames(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	dir, _ := ioutil.TempDir("", "gotracer")
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "animation.png")
+
+	code := run([]string{"render", "-frames", "3-5", "-fps", "2", "-width", "8", "-o", output, "scenes/animation.yml"}, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+
+	for _, name := range []string{"animation.0003.png", "animation.0004.png", "animation.0005.png"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.Nil(t, err, name)
+	}
+
+	_, err := os.Stat(filepath.Join(dir, "animation.0002.png"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRenderAnimationDefaultsToSceneDuration(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	dir, _ := ioutil.TempDir("", "gotracer")
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "animation.png")
+
+	code := run([]string{"render", "-fps", "1", "-width", "8", "-o", output, "scenes/animation.yml"}, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code)
+
+	for _, name := range []string{"animation.0000.png", "animation.0001.png", "animation.0002.png"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.Nil(t, err, name)
+	}
+
+	_, err := os.Stat(filepath.Join(dir, "animation.0003.png"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRenderFlagErrorsNameTheFlag(t *testing.T) {
+	testCases := []struct {
+		args    []string
+		message string
+	}{
+		{[]string{"render", "-workers", "0", "circle"}, "-workers must be at least 1"},
+		{[]string{"render", "-aperture", "-2", "circle"}, "-aperture must not be negative"},
+		{[]string{"render", "-shutter-close", "-0.5", "circle"}, "-shutter-close must be in [0, 1]"},
+		{[]string{"render", "-fps", "0", "circle"}, "-fps must be positive"},
+		{[]string{"render", "-sampler", "halton", "no-such-scene"}, "halton"},
+	}
+
+	for _, test := range testCases {
+		var stdout, stderr bytes.Buffer
+		assert.Equal(t, exitUsage, run(test.args, &stdout, &stderr), strings.Join(test.args, " "))
+		assert.Contains(t, stderr.String(), test.message)
+		assert.Equal(t, 1, strings.Count(stderr.String(), "\n"), stderr.String())
+	}
+}
+
+func TestRenderFailures(t *testing.T) {
+	testCases := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"render"}, exitUsage},
+		{[]string{"render", "-workers", "0", "circle"}, exitUsage},
+		{[]string{"render", "-integrator", "toon", "circle"}, exitUsage},
+		{[]string{"render", "-aov", "depth,motion", "circle"}, exitUsage},
+		{[]string{"render", "-adaptive", "-samples", "4", "circle"}, exitUsage},
+		{[]string{"render", "-adaptive", "-filter", "mitchell", "circle"}, exitUsage},
+		{[]string{"render", "-adaptive", "-max-samples", "3", "circle"}, exitUsage},
+		{[]string{"render", "-photons", "-5", "circle"}, exitUsage},
+		{[]string{"render", "-photon-radius", "0", "circle"}, exitUsage},
+		{[]string{"render", "-spectral", "-wavelengths", "0", "circle"}, exitUsage},
+		{[]string{"render", "-blades", "2", "circle"}, exitUsage},
+		{[]string{"render", "-projection", "isometric", "circle"}, exitUsage},
+		{[]string{"render", "-projection", "orthographic", "circle"}, exitUsage},
+		{[]string{"render", "-stereo", "interlaced", "circle"}, exitUsage},
+		{[]string{"render", "-shutter-open", "0.6", "-shutter-close", "0.4", "circle"}, exitUsage},
+		{[]string{"render", "-shutter-close", "1.5", "circle"}, exitUsage},
+		{[]string{"render", "-shutter-open", "-3", "circle"}, exitUsage},
+		{[]string{"render", "-aperture", "-0.5", "circle"}, exitUsage},
+		{[]string{"render", "-stereo", "anaglyph", "-interocular", "0", "circle"}, exitUsage},
+		{[]string{"render", "-frames", "5-2", "circle"}, exitUsage},
+		{[]string{"render", "-frames", "x", "circle"}, exitUsage},
+		{[]string{"render", "-frames", "0-1", "-fps", "0", "circle"}, exitUsage},
+		{[]string{"render", "no-such-scene"}, exitFailure},
+		{[]string{"render", "-o", "circle.gif", "-width", "4", "circle"}, exitFailure},
+		{[]string{"info", "no-such-scene"}, exitFailure},
+	}
+
+	for _, test := range testCases {
+		var stdout, stderr bytes.Buffer
+		assert.Equal(t, test.code, run(test.args, &stdout, &stderr), strings.Join(test.args, " "))
+	}
+}
+
+func TestInfoDescribesScene(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitOK, run([]string{"info", "table"}, &stdout, &stderr))
+	assert.True(t, strings.Contains(stdout.String(), "1920x1080"))
+	assert.True(t, strings.Contains(stdout.String(), "cube"))
+}
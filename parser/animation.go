@@ -0,0 +1,212 @@
+package parser
+
+import (
+	"gotracer/internal"
+	"math"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+type keyframeResolver struct {
+	time          float64
+	frameDuration float64
+	duration      float64
+}
+
+func (k *keyframeResolver) resolve(node *yaml.Node) (*yaml.Node, error) {
+	if isKeyframes(node) {
+		return k.evaluate(node.Content[1], k.time)
+	}
+
+	resolved := *node
+	resolved.Content = make([]*yaml.Node, len(node.Content))
+
+	for i, child := range node.Content {
+		var err error
+
+		if resolved.Content[i], err = k.resolve(child); err != nil {
+			return nil, err
+		}
+	}
+
+	transform := mappingValue(node, "transform")
+
+	if k.frameDuration > 0 && mappingValue(node, "add") != nil && transform != nil && containsKeyframes(transform) &&
+		mappingValue(node, "end-transform") == nil {
+		shutter := &keyframeResolver{time: k.time + k.frameDuration}
+		end, err := shutter.resolve(transform)
+
+		if err != nil {
+			return nil, err
+		}
+
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "end-transform", Line: transform.Line}
+		resolved.Content = append(resolved.Content, key, end)
+	}
+
+	return &resolved, nil
+}
+
+func (k *keyframeResolver) evaluate(list *yaml.Node, time float64) (*yaml.Node, error) {
+	if list.Kind != yaml.SequenceNode || len(list.Content) == 0 {
+		return nil, errorAt(list, "keyframes must be a non-empty list")
+	}
+
+	keyframes := make([]internal.Keyframe, len(list.Content))
+	values := make([]*yaml.Node, len(list.Content))
+
+	for i, item := range list.Content {
+		if item.Kind != yaml.MappingNode {
+			return nil, errorAt(item, "keyframe must be a mapping with time and value")
+		}
+		if err := checkKeys(item, "time", "value", "interpolation", "ease"); err != nil {
+			return nil, err
+		}
+		if err := requireFloat(item, "time", &keyframes[i].Time); err != nil {
+			return nil, err
+		}
+
+		if values[i] = mappingValue(item, "value"); values[i] == nil {
+			return nil, errorAt(item, "missing value")
+		}
+
+		if node := mappingValue(item, "interpolation"); node != nil {
+			var err error
+
+			if keyframes[i].Interpolation, err = internal.ParseInterpolation(node.Value); err != nil {
+				return nil, errorAt(node, "%v", err)
+			}
+		}
+
+		keyframes[i].Handles = internal.EaseInOut
+
+		if mappingValue(item, "ease") != nil {
+			var handles []float64
+
+			if err := requireFloats(item, "ease", &handles); err != nil {
+				return nil, err
+			}
+
+			if len(handles) != 4 {
+				return nil, errorAt(item, "ease must be [x1, y1, x2, y2]")
+			}
+
+			copy(keyframes[i].Handles[:], handles)
+		}
+
+		keyframes[i].Value = numericLeaves(values[i], nil)
+
+		if i > 0 && keyframes[i-1].Interpolation != internal.InterpolateStep && !sameShape(values[i-1], values[i]) {
+			return nil, errorAt(values[i], "keyframe value must match the previous value's structure unless that keyframe uses step interpolation")
+		}
+	}
+
+	track, err := internal.NewTrack(keyframes...)
+
+	if err != nil {
+		return nil, errorAt(list, "%v", err)
+	}
+
+	k.duration = math.Max(k.duration, track.Duration())
+	i := track.KeyframeAt(time)
+	integers := integerLeaves(values[i], nil)
+
+	if i+1 < len(values) && keyframes[i].Interpolation != internal.InterpolateStep {
+		for j, integer := range integerLeaves(values[i+1], nil) {
+			integers[j] = integers[j] && integer
+		}
+	}
+
+	value, _, _ := fillNumbers(values[i], track.ValueAt(time), integers)
+
+	return value, nil
+}
+
+func isKeyframes(node *yaml.Node) bool {
+	return node.Kind == yaml.MappingNode && len(node.Content) == 2 && node.Content[0].Value == "keyframes"
+}
+
+func containsKeyframes(node *yaml.Node) bool {
+	if isKeyframes(node) {
+		return true
+	}
+
+	for _, child := range node.Content {
+		if containsKeyframes(child) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isNumber(node *yaml.Node) bool {
+	tag := node.ShortTag()
+	return node.Kind == yaml.ScalarNode && (tag == "!!int" || tag == "!!float")
+}
+
+func numericLeaves(node *yaml.Node, values []float64) []float64 {
+	if isNumber(node) {
+		value, _ := strconv.ParseFloat(node.Value, 64)
+		return append(values, value)
+	}
+
+	for _, child := range node.Content {
+		values = numericLeaves(child, values)
+	}
+
+	return values
+}
+
+func integerLeaves(node *yaml.Node, integers []bool) []bool {
+	if isNumber(node) {
+		return append(integers, node.ShortTag() == "!!int")
+	}
+
+	for _, child := range node.Content {
+		integers = integerLeaves(child, integers)
+	}
+
+	return integers
+}
+
+func sameShape(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind || len(a.Content) != len(b.Content) {
+		return false
+	}
+
+	if a.Kind == yaml.ScalarNode {
+		return (isNumber(a) && isNumber(b)) || a.Value == b.Value
+	}
+
+	for i := range a.Content {
+		if !sameShape(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func fillNumbers(node *yaml.Node, values []float64, integers []bool) (*yaml.Node, []float64, []bool) {
+	filled := *node
+
+	if isNumber(node) {
+		if integers[0] {
+			filled.Tag, filled.Value = "!!int", strconv.Itoa(int(math.Round(values[0])))
+		} else {
+			filled.Tag, filled.Value = "!!float", strconv.FormatFloat(values[0], 'g', -1, 64)
+		}
+
+		return &filled, values[1:], integers[1:]
+	}
+
+	filled.Content = make([]*yaml.Node, len(node.Content))
+
+	for i, child := range node.Content {
+		filled.Content[i], values, integers = fillNumbers(child, values, integers)
+	}
+
+	return &filled, values, integers
+}
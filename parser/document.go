@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"fmt"
+	"gotracer/internal"
+	"io/ioutil"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Document struct {
+	root   yaml.Node
+	dir    string
+	path   string
+	assets *assetCache
+}
+
+func LoadDocument(path string) (*Document, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := parseDocument(string(data), filepath.Dir(path))
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	doc.path = path
+
+	return doc, nil
+}
+
+func ParseDocument(sceneData string) (*Document, error) {
+	return parseDocument(sceneData, ".")
+}
+
+func parseDocument(sceneData, dir string) (*Document, error) {
+	doc := &Document{dir: dir, assets: newAssetCache()}
+
+	if err := yaml.Unmarshal([]byte(sceneData), &doc.root); err != nil {
+		return nil, err
+	}
+
+	if len(doc.root.Content) == 0 {
+		return nil, errorAt(&doc.root, "scene is empty")
+	}
+
+	return doc, nil
+}
+
+func (d *Document) SceneAt(time, frameDuration float64) (*Scene, error) {
+	scene, err := d.sceneAt(time, frameDuration)
+
+	if err != nil && d.path != "" {
+		return nil, fmt.Errorf("%s: %w", d.path, err)
+	}
+
+	return scene, err
+}
+
+func (d *Document) sceneAt(time, frameDuration float64) (*Scene, error) {
+	p := &sceneParser{
+		dir:        d.dir,
+		assets:     d.assets,
+		defines:    make(map[string]*yaml.Node),
+		transforms: make(map[string]internal.Matrix),
+		scene:      &Scene{World: internal.NewWorld()},
+	}
+
+	keyframes := &keyframeResolver{time: time, frameDuration: frameDuration}
+	doc, err := keyframes.resolve(d.root.Content[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.Kind != yaml.SequenceNode {
+		return nil, errorAt(doc, "scene must be a list of add/define entries")
+	}
+
+	for _, item := range doc.Content {
+		if err := p.parseItem(item); err != nil {
+			return nil, err
+		}
+	}
+
+	if !p.hasCamera {
+		return nil, errorAt(doc, "scene has no camera")
+	}
+
+	if p.autofocus {
+		camera := p.scene.Camera
+		distance, ok := internal.AutoFocus(camera, p.scene.World, camera.Hsize/2, camera.Vsize/2)
+
+		if !ok {
+			return nil, errorAt(doc, "camera autofocus found no object at the image centre")
+		}
+
+		p.scene.Camera.FocalDistance = distance
+	}
+
+	if p.hasMotion && !p.hasShutter {
+		p.scene.Camera.ShutterClose = 1
+	}
+
+	p.scene.Duration = keyframes.duration
+	p.scene.World.AssignShapeIDs()
+
+	return p.scene, nil
+}
+
+type assetCache struct {
+	files        map[string]string
+	environments map[string]*internal.EnvironmentLight
+}
+
+func newAssetCache() *assetCache {
+	return &assetCache{
+		files:        make(map[string]string),
+		environments: make(map[string]*internal.EnvironmentLight),
+	}
+}
+
+func (c *assetCache) file(path string) (string, error) {
+	if data, ok := c.files[path]; ok {
+		return data, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	c.files[path] = string(data)
+
+	return c.files[path], nil
+}
+
+func (c *assetCache) environment(path string) (*internal.EnvironmentLight, error) {
+	env, ok := c.environments[path]
+
+	if !ok {
+		image, err := internal.LoadHDR(path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		env = internal.NewEnvironmentLight(image)
+		c.environments[path] = env
+	}
+
+	light := *env
+
+	return &light, nil
+}
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"gotracer/internal"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocumentLoadsFilesOnceAcrossFrames(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "gotracer")
+	defer os.RemoveAll(dir)
+
+	image := internal.NewCanvas(4, 2)
+	image.WritePixelAtCoord(1, 0, internal.NewColor(2, 2, 2))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "sky.hdr"), image.ToHDR(), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "tri.obj"), []byte("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n"), 0644))
+
+	path := filepath.Join(dir, "scene.yml")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(cameraYAML+`
+- add: environment
+  file: sky.hdr
+  intensity:
+    keyframes:
+      - { time: 0, value: 1 }
+      - { time: 1, value: 3 }
+- add: obj
+  file: tri.obj
+`), 0644))
+
+	doc, err := LoadDocument(path)
+	assert.Nil(t, err)
+
+	first, err := doc.SceneAt(0, 0)
+	assert.Nil(t, err)
+
+	for _, name := range []string{"scene.yml", "sky.hdr", "tri.obj"} {
+		assert.Nil(t, os.Remove(filepath.Join(dir, name)))
+	}
+
+	last, err := doc.SceneAt(1, 0)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1.0, first.World.Environment.Intensity)
+	assert.Equal(t, 3.0, last.World.Environment.Intensity)
+	assert.True(t, first.World.Environment.Map == last.World.Environment.Map)
+	assert.Equal(t, 1.0, first.Duration)
+	assert.Equal(t, 1, len(last.World.Objects))
+	assert.True(t, first.World.Objects[0] != last.World.Objects[0])
+}
+
+func TestDocumentErrorsNameTheFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "gotracer")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "scene.yml")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("- add: sphere\n"), 0644))
+
+	doc, err := LoadDocument(path)
+	assert.Nil(t, err)
+
+	_, err = doc.SceneAt(0, 0)
+	assert.Contains(t, err.Error(), path)
+
+	_, err = ParseDocument("")
+	assert.NotNil(t, err)
+}
@@ -118,6 +118,16 @@ func (obj *Object) GetDefaultGroup() *internal.Group {
 	return obj.GetGroup("DefaultGroup")
 }
 
+func (obj *Object) TriangleCount() int {
+	count := 0
+
+	for _, g := range obj.Groups {
+		count += len(g.Children)
+	}
+
+	return count
+}
+
 func (obj *Object) ToGroup() *internal.Group {
 	group := internal.NewGroup()
 
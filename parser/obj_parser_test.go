@@ -136,6 +136,7 @@ f 1 3 4`
 
 	assert.True(t, includesG1)
 	assert.True(t, includesG2)
+	assert.Equal(t, 2, obj.TriangleCount())
 }
 
 func TestVertexNormalRecords(t *testing.T) {
@@ -0,0 +1,1537 @@
+package parser
+
+import (
+	"fmt"
+	"gotracer/internal"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Scene struct {
+	World    internal.World
+	Camera   internal.Camera
+	Duration float64
+}
+
+type SceneError struct {
+	Line    int
+	Message string
+}
+
+func (e *SceneError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+func errorAt(node *yaml.Node, format string, args ...interface{}) error {
+	return &SceneError{
+		Line:    node.Line,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+type sceneParser struct {
+	dir        string
+	assets     *assetCache
+	defines    map[string]*yaml.Node
+	transforms map[string]internal.Matrix
+	scene      *Scene
+	hasCamera  bool
+	autofocus  bool
+	hasShutter bool
+	hasMotion  bool
+	depth      int
+}
+
+const maxDefineDepth = 64
+
+func LoadSceneFile(path string) (*Scene, error) {
+	return LoadSceneFileAt(path, 0, 0)
+}
+
+func LoadSceneFileAt(path string, time, frameDuration float64) (*Scene, error) {
+	doc, err := LoadDocument(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.SceneAt(time, frameDuration)
+}
+
+func ParseSceneFile(sceneData string) (*Scene, error) {
+	return ParseSceneFileAt(sceneData, 0, 0)
+}
+
+func ParseSceneFileAt(sceneData string, time, frameDuration float64) (*Scene, error) {
+	return parseScene(sceneData, ".", time, frameDuration)
+}
+
+func parseScene(sceneData, dir string, time, frameDuration float64) (*Scene, error) {
+	doc, err := parseDocument(sceneData, dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.sceneAt(time, frameDuration)
+}
+
+func (p *sceneParser) parseItem(item *yaml.Node) error {
+	if item.Kind != yaml.MappingNode {
+		return errorAt(item, "expected a mapping")
+	}
+
+	if define := mappingValue(item, "define"); define != nil {
+		return p.parseDefine(item, define)
+	}
+
+	add := mappingValue(item, "add")
+
+	if add == nil {
+		return errorAt(item, "entry must contain either add or define")
+	}
+
+	switch add.Value {
+	case "camera":
+		return p.parseCamera(item)
+	case "light":
+		light, err := p.parseLight(item)
+
+		if err != nil {
+			return err
+		}
+
+		p.scene.World.Lights = append(p.scene.World.Lights, light)
+		return nil
+	case "background":
+		background, err := p.parseBackground(item)
+
+		if err != nil {
+			return err
+		}
+
+		p.scene.World.Background = background
+		return nil
+	case "environment":
+		env, err := p.parseEnvironment(item)
+
+		if err != nil {
+			return err
+		}
+
+		p.scene.World.Environment = env
+		return nil
+	case "medium":
+		medium, err := parseMedium(item, "add")
+
+		if err != nil {
+			return err
+		}
+
+		p.scene.World.Medium = medium
+		return nil
+	case "shadows":
+		if err := checkKeys(item, "add", "transmissive"); err != nil {
+			return err
+		}
+
+		return optionalBool(item, "transmissive", &p.scene.World.TransmissiveShadows)
+	default:
+		shape, err := p.parseShape(item)
+
+		if err != nil {
+			return err
+		}
+
+		p.scene.World.Objects = append(p.scene.World.Objects, shape)
+		return nil
+	}
+}
+
+func (p *sceneParser) parseDefine(item, define *yaml.Node) error {
+	if err := checkKeys(item, "define", "extend", "value"); err != nil {
+		return err
+	}
+
+	value := mappingValue(item, "value")
+
+	if value == nil {
+		return errorAt(item, "define %q has no value", define.Value)
+	}
+
+	if extend := mappingValue(item, "extend"); extend != nil {
+		base, ok := p.defines[extend.Value]
+
+		if !ok {
+			return errorAt(extend, "unknown definition %q", extend.Value)
+		}
+
+		merged, err := mergeNodes(base, value)
+
+		if err != nil {
+			return err
+		}
+
+		value = merged
+	}
+
+	delete(p.transforms, define.Value)
+
+	switch value.Kind {
+	case yaml.SequenceNode:
+		transform, err := p.parseTransform(value)
+
+		if err != nil {
+			return err
+		}
+
+		p.transforms[define.Value] = transform
+
+	case yaml.MappingNode:
+		if add := mappingValue(value, "add"); add != nil {
+			if base, ok := p.defines[add.Value]; ok {
+				resolved, err := mergeDefinedShape(value, base)
+
+				if err != nil {
+					return err
+				}
+
+				value = resolved
+			}
+		}
+	}
+
+	p.defines[define.Value] = value
+
+	return nil
+}
+
+func mergeNodes(base, value *yaml.Node) (*yaml.Node, error) {
+	if base.Kind != value.Kind {
+		return nil, errorAt(value, "cannot extend a definition of a different kind")
+	}
+
+	merged := *value
+
+	switch value.Kind {
+	case yaml.MappingNode:
+		merged.Content = nil
+
+		for i := 0; i < len(base.Content); i += 2 {
+			if mappingValue(value, base.Content[i].Value) == nil {
+				merged.Content = append(merged.Content, base.Content[i], base.Content[i+1])
+			}
+		}
+
+		merged.Content = append(merged.Content, value.Content...)
+
+	case yaml.SequenceNode:
+		merged.Content = append(append([]*yaml.Node{}, base.Content...), value.Content...)
+
+	default:
+		return nil, errorAt(value, "only mappings and lists can be extended")
+	}
+
+	return &merged, nil
+}
+
+func (p *sceneParser) parseCamera(item *yaml.Node) error {
+	if err := checkKeys(item, "add", "width", "height", "field-of-view", "from", "to", "up", "projection", "view-width",
+		"aperture", "f-stop", "focal-length", "focal-distance", "blades", "blade-rotation", "autofocus",
+		"shutter-open", "shutter-close"); err != nil {
+		return err
+	}
+
+	var width, height int
+	var fov, viewWidth float64
+	var from, to, up internal.Tuple
+
+	projection := internal.ProjectionPerspective
+
+	if projectionNode := mappingValue(item, "projection"); projectionNode != nil {
+		var err error
+
+		if projection, err = internal.ParseProjection(projectionNode.Value); err != nil {
+			return errorAt(projectionNode, "%v", err)
+		}
+	}
+
+	if err := requireInt(item, "width", &width); err != nil {
+		return err
+	}
+	if err := requireInt(item, "height", &height); err != nil {
+		return err
+	}
+
+	if projection == internal.ProjectionOrthographic {
+		if err := requireFloat(item, "view-width", &viewWidth); err != nil {
+			return err
+		}
+
+		if viewWidth <= 0 {
+			return errorAt(item, "camera view-width must be positive")
+		}
+	} else if projection == internal.ProjectionPerspective || projection.IsFisheye() {
+		if err := requireFloat(item, "field-of-view", &fov); err != nil {
+			return err
+		}
+	}
+
+	if err := requirePoint(item, "from", &from); err != nil {
+		return err
+	}
+	if err := requirePoint(item, "to", &to); err != nil {
+		return err
+	}
+	if err := requireVector(item, "up", &up); err != nil {
+		return err
+	}
+
+	if width <= 0 || height <= 0 {
+		return errorAt(item, "camera width and height must be positive")
+	}
+
+	camera := internal.NewCamera(width, height, fov)
+
+	if projection == internal.ProjectionOrthographic {
+		camera = internal.NewOrthographicCamera(width, height, viewWidth)
+	} else if projection.IsPanoramic() {
+		camera = internal.NewPanoramicCamera(width, height, projection, fov)
+	}
+
+	camera.Transform = internal.ViewTransform(from, to, up)
+
+	if err := p.parseLens(item, &camera); err != nil {
+		return err
+	}
+	if err := p.parseShutter(item, &camera); err != nil {
+		return err
+	}
+
+	p.scene.Camera = camera
+	p.hasCamera = true
+
+	return nil
+}
+
+func (p *sceneParser) parseLens(item *yaml.Node, camera *internal.Camera) error {
+	if err := optionalFloat(item, "aperture", &camera.Aperture); err != nil {
+		return err
+	}
+	if err := optionalFloat(item, "focal-distance", &camera.FocalDistance); err != nil {
+		return err
+	}
+	if err := optionalFloat(item, "blade-rotation", &camera.BladeRotation); err != nil {
+		return err
+	}
+	if err := optionalBool(item, "autofocus", &p.autofocus); err != nil {
+		return err
+	}
+
+	if mappingValue(item, "blades") != nil {
+		if err := requireInt(item, "blades", &camera.Blades); err != nil {
+			return err
+		}
+
+		if camera.Blades < 3 {
+			return errorAt(item, "camera blades must be at least 3")
+		}
+	}
+
+	if mappingValue(item, "f-stop") != nil {
+		var fStop, focalLength float64
+
+		if mappingValue(item, "aperture") != nil {
+			return errorAt(item, "camera takes either aperture or f-stop, not both")
+		}
+		if err := requireFloat(item, "f-stop", &fStop); err != nil {
+			return err
+		}
+		if err := requireFloat(item, "focal-length", &focalLength); err != nil {
+			return err
+		}
+
+		if fStop <= 0 || focalLength <= 0 {
+			return errorAt(item, "camera f-stop and focal-length must be positive")
+		}
+
+		camera.Aperture = internal.ApertureForFStop(focalLength, fStop)
+	}
+
+	if camera.Aperture < 0 || camera.FocalDistance < 0 {
+		return errorAt(item, "camera aperture and focal-distance must not be negative")
+	}
+
+	return nil
+}
+
+func (p *sceneParser) parseShutter(item *yaml.Node, camera *internal.Camera) error {
+	p.hasShutter = mappingValue(item, "shutter-open") != nil || mappingValue(item, "shutter-close") != nil
+
+	if err := optionalFloat(item, "shutter-open", &camera.ShutterOpen); err != nil {
+		return err
+	}
+	if err := optionalFloat(item, "shutter-close", &camera.ShutterClose); err != nil {
+		return err
+	}
+
+	if camera.ShutterOpen < 0 || camera.ShutterClose > 1 || camera.ShutterOpen > camera.ShutterClose {
+		return errorAt(item, "camera shutter must satisfy 0 <= shutter-open <= shutter-close <= 1")
+	}
+
+	return nil
+}
+
+func (p *sceneParser) parseLight(item *yaml.Node) (internal.LightSource, error) {
+	var intensity internal.Color
+
+	if err := requireColor(item, "intensity", &intensity); err != nil {
+		return nil, err
+	}
+
+	if mappingValue(item, "corner") == nil {
+		if err := checkKeys(item, "add", "at", "intensity"); err != nil {
+			return nil, err
+		}
+
+		var at internal.Tuple
+
+		if err := requirePoint(item, "at", &at); err != nil {
+			return nil, err
+		}
+
+		return internal.NewPointLight(at, intensity), nil
+	}
+
+	if err := checkKeys(item, "add", "corner", "uvec", "usteps", "vvec", "vsteps", "jitter", "intensity"); err != nil {
+		return nil, err
+	}
+
+	var corner, uvec, vvec internal.Tuple
+	var usteps, vsteps int
+	var jitter bool
+
+	if err := requirePoint(item, "corner", &corner); err != nil {
+		return nil, err
+	}
+	if err := requireVector(item, "uvec", &uvec); err != nil {
+		return nil, err
+	}
+	if err := requireVector(item, "vvec", &vvec); err != nil {
+		return nil, err
+	}
+	if err := requireInt(item, "usteps", &usteps); err != nil {
+		return nil, err
+	}
+	if err := requireInt(item, "vsteps", &vsteps); err != nil {
+		return nil, err
+	}
+	if err := optionalBool(item, "jitter", &jitter); err != nil {
+		return nil, err
+	}
+
+	if usteps <= 0 || vsteps <= 0 {
+		return nil, errorAt(item, "area light usteps and vsteps must be positive")
+	}
+
+	light := internal.NewAreaLight(corner, uvec, usteps, vvec, vsteps, intensity)
+	light.Jitter = jitter
+
+	return light, nil
+}
+
+func (p *sceneParser) parseBackground(item *yaml.Node) (internal.Background, error) {
+	if err := checkKeys(item, "add", "color", "bottom", "top", "pattern"); err != nil {
+		return nil, err
+	}
+
+	if node := mappingValue(item, "pattern"); node != nil {
+		pattern, err := p.parsePattern(node)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return internal.NewPatternBackground(pattern), nil
+	}
+
+	if mappingValue(item, "color") != nil {
+		var color internal.Color
+
+		if err := requireColor(item, "color", &color); err != nil {
+			return nil, err
+		}
+
+		return internal.NewSolidBackground(color), nil
+	}
+
+	var bottom, top internal.Color
+
+	if err := requireColor(item, "bottom", &bottom); err != nil {
+		return nil, err
+	}
+	if err := requireColor(item, "top", &top); err != nil {
+		return nil, err
+	}
+
+	return internal.NewGradientBackground(bottom, top), nil
+}
+
+func (p *sceneParser) parseEnvironment(item *yaml.Node) (*internal.EnvironmentLight, error) {
+	if err := checkKeys(item, "add", "file", "intensity", "rotation", "samples"); err != nil {
+		return nil, err
+	}
+
+	fileNode := mappingValue(item, "file")
+
+	if fileNode == nil {
+		return nil, errorAt(item, "environment is missing file")
+	}
+
+	path := fileNode.Value
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.dir, path)
+	}
+
+	env, err := p.assets.environment(path)
+
+	if err != nil {
+		return nil, errorAt(fileNode, "%v", err)
+	}
+
+	if err := optionalFloat(item, "intensity", &env.Intensity); err != nil {
+		return nil, err
+	}
+	if err := optionalFloat(item, "rotation", &env.Rotation); err != nil {
+		return nil, err
+	}
+
+	if mappingValue(item, "samples") != nil {
+		if err := requireInt(item, "samples", &env.Samples); err != nil {
+			return nil, err
+		}
+	}
+
+	return env, nil
+}
+
+func parseMedium(node *yaml.Node, extraKeys ...string) (*internal.Medium, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, errorAt(node, "medium must be a mapping")
+	}
+
+	if err := checkKeys(node, append([]string{"absorption", "scattering", "anisotropy", "steps"}, extraKeys...)...); err != nil {
+		return nil, err
+	}
+
+	medium := internal.NewMedium(internal.NewColor(0, 0, 0), internal.NewColor(0, 0, 0), 0)
+
+	if err := optionalColor(node, "absorption", &medium.Absorption); err != nil {
+		return nil, err
+	}
+	if err := optionalColor(node, "scattering", &medium.Scattering); err != nil {
+		return nil, err
+	}
+	if err := optionalFloat(node, "anisotropy", &medium.Anisotropy); err != nil {
+		return nil, err
+	}
+
+	if mappingValue(node, "steps") != nil {
+		if err := requireInt(node, "steps", &medium.Steps); err != nil {
+			return nil, err
+		}
+	}
+
+	if medium.Anisotropy <= -1 || medium.Anisotropy >= 1 {
+		return nil, errorAt(node, "medium anisotropy must be between -1 and 1")
+	}
+
+	return medium, nil
+}
+
+func parseDispersion(node *yaml.Node) (*internal.Dispersion, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, errorAt(node, "dispersion must be a mapping")
+	}
+
+	modelNode := mappingValue(node, "model")
+
+	if modelNode == nil {
+		return nil, errorAt(node, "dispersion is missing model")
+	}
+
+	model, err := internal.ParseDispersionModel(modelNode.Value)
+
+	if err != nil {
+		return nil, errorAt(modelNode, "%v", err)
+	}
+
+	if model == internal.DispersionCauchy {
+		var coefficients []float64
+
+		if err := checkKeys(node, "model", "coefficients"); err != nil {
+			return nil, err
+		}
+		if err := requireFloats(node, "coefficients", &coefficients); err != nil {
+			return nil, err
+		}
+
+		if len(coefficients) > 3 {
+			return nil, errorAt(node, "cauchy dispersion takes at most three coefficients")
+		}
+
+		return internal.NewCauchyDispersion(coefficients...), nil
+	}
+
+	var b, c []float64
+
+	if err := checkKeys(node, "model", "b", "c"); err != nil {
+		return nil, err
+	}
+	if err := requireFloats(node, "b", &b); err != nil {
+		return nil, err
+	}
+	if err := requireFloats(node, "c", &c); err != nil {
+		return nil, err
+	}
+
+	dispersion, err := internal.NewSellmeierDispersion(b, c)
+
+	if err != nil {
+		return nil, errorAt(node, "%v", err)
+	}
+
+	return dispersion, nil
+}
+
+var shapeKeys = []string{"add", "material", "transform", "end-transform", "shadow"}
+
+func (p *sceneParser) parseShape(item *yaml.Node) (internal.Shape, error) {
+	if item.Kind != yaml.MappingNode {
+		return nil, errorAt(item, "expected a shape mapping")
+	}
+
+	add := mappingValue(item, "add")
+
+	if add == nil {
+		return nil, errorAt(item, "shape is missing add")
+	}
+
+	if def, ok := p.defines[add.Value]; ok {
+		return p.parseDefinedShape(item, def)
+	}
+
+	var shape internal.Shape
+	var err error
+
+	switch add.Value {
+	case "sphere":
+		err = checkKeys(item, shapeKeys...)
+		shape = internal.NewSphere()
+
+	case "plane":
+		err = checkKeys(item, shapeKeys...)
+		shape = internal.NewPlane()
+
+	case "cube":
+		err = checkKeys(item, shapeKeys...)
+		shape = internal.NewCube()
+
+	case "cylinder":
+		cyl := internal.NewCylinder()
+		err = parseCylinderBounds(item, &cyl.Minimum, &cyl.Maximum, &cyl.Closed)
+		shape = cyl
+
+	case "cone":
+		cone := internal.NewCone()
+		err = parseCylinderBounds(item, &cone.Minimum, &cone.Maximum, &cone.Closed)
+		shape = cone
+
+	case "triangle":
+		shape, err = parseTriangle(item)
+
+	case "smooth-triangle":
+		shape, err = parseSmoothTriangle(item)
+
+	case "group":
+		shape, err = p.parseGroup(item)
+
+	case "csg":
+		shape, err = p.parseCSG(item)
+
+	case "obj":
+		shape, err = p.parseObj(item)
+
+	case "volume":
+		shape, err = parseVolume(item)
+
+	default:
+		return nil, errorAt(add, "unknown shape %q", add.Value)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.applyShapeAttributes(item, shape); err != nil {
+		return nil, err
+	}
+
+	return shape, nil
+}
+
+func parseVolume(item *yaml.Node) (internal.Shape, error) {
+	if err := checkKeys(item, append(shapeKeys, "min", "max", "density", "absorption", "scattering",
+		"anisotropy", "max-density", "method", "step")...); err != nil {
+		return nil, err
+	}
+
+	min, max := internal.NewPoint(-1, -1, -1), internal.NewPoint(1, 1, 1)
+
+	if mappingValue(item, "min") != nil {
+		if err := requirePoint(item, "min", &min); err != nil {
+			return nil, err
+		}
+	}
+	if mappingValue(item, "max") != nil {
+		if err := requirePoint(item, "max", &max); err != nil {
+			return nil, err
+		}
+	}
+
+	if min.X >= max.X || min.Y >= max.Y || min.Z >= max.Z {
+		return nil, errorAt(item, "volume min must be below max on every axis")
+	}
+
+	densityNode := mappingValue(item, "density")
+
+	if densityNode == nil {
+		return nil, errorAt(item, "volume is missing density")
+	}
+
+	density, err := parseDensity(densityNode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	volume := internal.NewVolume(internal.NewBoundingBox(min, max), density)
+
+	if err := optionalColor(item, "absorption", &volume.Absorption); err != nil {
+		return nil, err
+	}
+	if err := optionalColor(item, "scattering", &volume.Scattering); err != nil {
+		return nil, err
+	}
+	if err := optionalFloat(item, "anisotropy", &volume.Anisotropy); err != nil {
+		return nil, err
+	}
+	if err := optionalFloat(item, "max-density", &volume.MaxDensity); err != nil {
+		return nil, err
+	}
+
+	if b, ok := density.(internal.BoundedDensity); ok && volume.MaxDensity < b.DensityBound() {
+		return nil, errorAt(item, "max-density %g is below the density field's maximum %g", volume.MaxDensity, b.DensityBound())
+	}
+	if err := optionalFloat(item, "step", &volume.StepSize); err != nil {
+		return nil, err
+	}
+
+	if method := mappingValue(item, "method"); method != nil {
+		if volume.Method, err = internal.ParseVolumeMethod(method.Value); err != nil {
+			return nil, errorAt(method, "%v", err)
+		}
+	}
+
+	if err := volume.Validate(); err != nil {
+		return nil, errorAt(item, "%v", err)
+	}
+
+	return volume, nil
+}
+
+func parseDensity(node *yaml.Node) (internal.DensityField, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, errorAt(node, "density must be a mapping")
+	}
+
+	typeNode := mappingValue(node, "type")
+
+	if typeNode == nil {
+		return nil, errorAt(node, "density is missing type")
+	}
+
+	switch typeNode.Value {
+	case "constant":
+		if err := checkKeys(node, "type", "value"); err != nil {
+			return nil, err
+		}
+
+		var value float64
+
+		if err := requireFloat(node, "value", &value); err != nil {
+			return nil, err
+		}
+
+		return internal.ConstantDensity(value), nil
+
+	case "noise":
+		if err := checkKeys(node, "type", "scale", "octaves", "threshold", "gain"); err != nil {
+			return nil, err
+		}
+
+		noise := internal.NewNoiseDensity()
+
+		if err := optionalFloat(node, "scale", &noise.Scale); err != nil {
+			return nil, err
+		}
+		if err := optionalFloat(node, "threshold", &noise.Threshold); err != nil {
+			return nil, err
+		}
+		if err := optionalFloat(node, "gain", &noise.Gain); err != nil {
+			return nil, err
+		}
+
+		if mappingValue(node, "octaves") != nil {
+			if err := requireInt(node, "octaves", &noise.Octaves); err != nil {
+				return nil, err
+			}
+		}
+
+		return noise, nil
+
+	default:
+		return nil, errorAt(typeNode, "unknown density type %q", typeNode.Value)
+	}
+}
+
+func (p *sceneParser) parseDefinedShape(item, def *yaml.Node) (internal.Shape, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+
+	if p.depth > maxDefineDepth {
+		return nil, errorAt(item, "shape definitions are nested too deeply")
+	}
+
+	merged, err := mergeDefinedShape(item, def)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return p.parseShape(merged)
+}
+
+func mergeDefinedShape(item, def *yaml.Node) (*yaml.Node, error) {
+	if def.Kind != yaml.MappingNode || mappingValue(def, "add") == nil {
+		return nil, errorAt(item, "definition %q is not a shape", mappingValue(item, "add").Value)
+	}
+
+	merged, err := mergeNodes(def, item)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(merged.Content); i += 2 {
+		if merged.Content[i].Value == "add" {
+			merged.Content[i+1] = mappingValue(def, "add")
+		}
+	}
+
+	defTransform := mappingValue(def, "transform")
+	itemTransform := mappingValue(item, "transform")
+
+	if defTransform != nil && itemTransform != nil {
+		transform, err := mergeNodes(defTransform, itemTransform)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < len(merged.Content); i += 2 {
+			if merged.Content[i].Value == "transform" {
+				merged.Content[i+1] = transform
+			}
+		}
+	}
+
+	return merged, nil
+}
+
+func (p *sceneParser) applyShapeAttributes(item *yaml.Node, shape internal.Shape) error {
+	transform, err := p.optionalTransform(item, "transform")
+
+	if err != nil {
+		return err
+	}
+
+	shape.SetTransform(transform)
+
+	if mappingValue(item, "end-transform") != nil {
+		end, err := p.optionalTransform(item, "end-transform")
+
+		if err != nil {
+			return err
+		}
+
+		internal.SetShapeMotion(shape, transform, end)
+		p.hasMotion = true
+	}
+
+	if node := mappingValue(item, "material"); node != nil {
+		material, err := p.parseMaterial(node)
+
+		if err != nil {
+			return err
+		}
+
+		shape.SetMaterial(material)
+	}
+
+	hasShadow := true
+
+	if err := optionalBool(item, "shadow", &hasShadow); err != nil {
+		return err
+	}
+
+	setShadow(shape, hasShadow)
+
+	return nil
+}
+
+func setShadow(shape internal.Shape, hasShadow bool) {
+	switch s := shape.(type) {
+	case *internal.Sphere:
+		s.HasShadow = hasShadow
+	case *internal.Plane:
+		s.HasShadow = hasShadow
+	case *internal.Cube:
+		s.HasShadow = hasShadow
+	case *internal.Cylinder:
+		s.HasShadow = hasShadow
+	case *internal.Cone:
+		s.HasShadow = hasShadow
+	case *internal.Triangle:
+		s.HasShadow = hasShadow
+	case *internal.SmoothTriangle:
+		s.HasShadow = hasShadow
+	case *internal.Group:
+		s.HasShadow = hasShadow
+	case *internal.CSG:
+		s.HasShadow = hasShadow
+	case *internal.Volume:
+		s.HasShadow = hasShadow
+	}
+}
+
+func parseCylinderBounds(item *yaml.Node, min, max *float64, closed *bool) error {
+	if err := checkKeys(item, append(shapeKeys, "min", "max", "closed")...); err != nil {
+		return err
+	}
+	if err := optionalFloat(item, "min", min); err != nil {
+		return err
+	}
+	if err := optionalFloat(item, "max", max); err != nil {
+		return err
+	}
+
+	return optionalBool(item, "closed", closed)
+}
+
+func parseTriangle(item *yaml.Node) (internal.Shape, error) {
+	if err := checkKeys(item, append(shapeKeys, "p1", "p2", "p3")...); err != nil {
+		return nil, err
+	}
+
+	var p1, p2, p3 internal.Tuple
+
+	if err := requirePoint(item, "p1", &p1); err != nil {
+		return nil, err
+	}
+	if err := requirePoint(item, "p2", &p2); err != nil {
+		return nil, err
+	}
+	if err := requirePoint(item, "p3", &p3); err != nil {
+		return nil, err
+	}
+
+	return internal.NewTriangle(p1, p2, p3), nil
+}
+
+func parseSmoothTriangle(item *yaml.Node) (internal.Shape, error) {
+	if err := checkKeys(item, append(shapeKeys, "p1", "p2", "p3", "n1", "n2", "n3")...); err != nil {
+		return nil, err
+	}
+
+	var p1, p2, p3, n1, n2, n3 internal.Tuple
+
+	if err := requirePoint(item, "p1", &p1); err != nil {
+		return nil, err
+	}
+	if err := requirePoint(item, "p2", &p2); err != nil {
+		return nil, err
+	}
+	if err := requirePoint(item, "p3", &p3); err != nil {
+		return nil, err
+	}
+	if err := requireVector(item, "n1", &n1); err != nil {
+		return nil, err
+	}
+	if err := requireVector(item, "n2", &n2); err != nil {
+		return nil, err
+	}
+	if err := requireVector(item, "n3", &n3); err != nil {
+		return nil, err
+	}
+
+	return internal.NewSmoothTriangle(p1, p2, p3, n1, n2, n3), nil
+}
+
+func (p *sceneParser) parseGroup(item *yaml.Node) (internal.Shape, error) {
+	if err := checkKeys(item, append(shapeKeys, "children")...); err != nil {
+		return nil, err
+	}
+
+	group := internal.NewGroup()
+	children := mappingValue(item, "children")
+
+	if children == nil {
+		return group, nil
+	}
+
+	if children.Kind != yaml.SequenceNode {
+		return nil, errorAt(children, "children must be a list of shapes")
+	}
+
+	for _, childNode := range children.Content {
+		child, err := p.parseShape(childNode)
+
+		if err != nil {
+			return nil, err
+		}
+
+		group.AddChild(child)
+	}
+
+	return group, nil
+}
+
+func (p *sceneParser) parseCSG(item *yaml.Node) (internal.Shape, error) {
+	if err := checkKeys(item, append(shapeKeys, "operation", "left", "right")...); err != nil {
+		return nil, err
+	}
+
+	opNode := mappingValue(item, "operation")
+
+	if opNode == nil {
+		return nil, errorAt(item, "csg is missing operation")
+	}
+
+	var op internal.CSGOperation
+
+	switch opNode.Value {
+	case "union":
+		op = internal.CSGUnion
+	case "intersection":
+		op = internal.CSGIntersect
+	case "difference":
+		op = internal.CSGDifference
+	default:
+		return nil, errorAt(opNode, "unknown csg operation %q", opNode.Value)
+	}
+
+	leftNode, rightNode := mappingValue(item, "left"), mappingValue(item, "right")
+
+	if leftNode == nil || rightNode == nil {
+		return nil, errorAt(item, "csg requires both left and right shapes")
+	}
+
+	left, err := p.parseShape(leftNode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := p.parseShape(rightNode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return internal.NewCSG(op, left, right), nil
+}
+
+func (p *sceneParser) parseObj(item *yaml.Node) (internal.Shape, error) {
+	if err := checkKeys(item, append(shapeKeys, "file")...); err != nil {
+		return nil, err
+	}
+
+	fileNode := mappingValue(item, "file")
+
+	if fileNode == nil {
+		return nil, errorAt(item, "obj is missing file")
+	}
+
+	path := fileNode.Value
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.dir, path)
+	}
+
+	data, err := p.assets.file(path)
+
+	if err != nil {
+		return nil, errorAt(fileNode, "%v", err)
+	}
+
+	obj, ignored := ParseObjectFile(data)
+
+	if obj.TriangleCount() == 0 {
+		return nil, errorAt(fileNode, "obj file %q has no faces (%d lines ignored)", fileNode.Value, ignored)
+	}
+
+	return obj.ToGroup(), nil
+}
+
+func (p *sceneParser) parseMaterial(node *yaml.Node) (internal.Material, error) {
+	material := internal.NewDefaultMaterial()
+
+	if node.Kind == yaml.ScalarNode {
+		def, ok := p.defines[node.Value]
+
+		if !ok {
+			return material, errorAt(node, "unknown material %q", node.Value)
+		}
+
+		node = def
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return material, errorAt(node, "material must be a mapping or a defined name")
+	}
+
+	if err := checkKeys(node, "color", "pattern", "ambient", "diffuse", "specular", "shininess",
+		"reflective", "transparency", "refractive-index", "medium", "absorption", "absorption-density", "dispersion",
+		"fresnel", "conductor-eta", "conductor-k"); err != nil {
+		return material, err
+	}
+
+	if err := optionalColor(node, "color", &material.Color); err != nil {
+		return material, err
+	}
+
+	if err := optionalColor(node, "absorption", &material.Absorption); err != nil {
+		return material, err
+	}
+	if err := optionalColor(node, "conductor-eta", &material.ConductorEta); err != nil {
+		return material, err
+	}
+	if err := optionalColor(node, "conductor-k", &material.ConductorK); err != nil {
+		return material, err
+	}
+
+	if fresnelNode := mappingValue(node, "fresnel"); fresnelNode != nil {
+		fresnel, err := internal.ParseFresnelMode(fresnelNode.Value)
+
+		if err != nil {
+			return material, errorAt(fresnelNode, "%v", err)
+		}
+
+		material.Fresnel = fresnel
+	}
+
+	if material.Fresnel == internal.FresnelConductor && mappingValue(node, "conductor-eta") == nil {
+		return material, errorAt(node, "conductor fresnel needs conductor-eta")
+	}
+
+	floats := []struct {
+		key   string
+		field *float64
+	}{
+		{"ambient", &material.Ambient},
+		{"diffuse", &material.Diffuse},
+		{"specular", &material.Specular},
+		{"shininess", &material.Shininess},
+		{"reflective", &material.Reflective},
+		{"transparency", &material.Transparency},
+		{"refractive-index", &material.RefractiveIndex},
+		{"absorption-density", &material.AbsorptionDensity},
+	}
+
+	for _, f := range floats {
+		if err := optionalFloat(node, f.key, f.field); err != nil {
+			return material, err
+		}
+	}
+
+	if (material.Fresnel == internal.FresnelSchlick || material.Fresnel == internal.FresnelDielectric) && material.RefractiveIndex == 1 {
+		return material, errorAt(node, "%s fresnel needs a refractive-index other than 1", material.Fresnel)
+	}
+
+	if mediumNode := mappingValue(node, "medium"); mediumNode != nil {
+		medium, err := parseMedium(mediumNode)
+
+		if err != nil {
+			return material, err
+		}
+
+		material.Medium = medium
+	}
+
+	if dispersionNode := mappingValue(node, "dispersion"); dispersionNode != nil {
+		dispersion, err := parseDispersion(dispersionNode)
+
+		if err != nil {
+			return material, err
+		}
+
+		material.Dispersion = dispersion
+	}
+
+	if patternNode := mappingValue(node, "pattern"); patternNode != nil {
+		pattern, err := p.parsePattern(patternNode)
+
+		if err != nil {
+			return material, err
+		}
+
+		material.SetPattern(pattern)
+	}
+
+	return material, nil
+}
+
+func (p *sceneParser) parsePattern(node *yaml.Node) (internal.Pattern, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, errorAt(node, "pattern must be a mapping")
+	}
+
+	if err := checkKeys(node, "type", "colors", "transform"); err != nil {
+		return nil, err
+	}
+
+	typeNode := mappingValue(node, "type")
+
+	if typeNode == nil {
+		return nil, errorAt(node, "pattern is missing type")
+	}
+
+	var a, b internal.Color
+
+	if typeNode.Value != "test" {
+		colors := mappingValue(node, "colors")
+
+		if colors == nil || colors.Kind != yaml.SequenceNode || len(colors.Content) != 2 {
+			return nil, errorAt(node, "pattern requires exactly two colors")
+		}
+
+		if err := decodeColor(colors.Content[0], &a); err != nil {
+			return nil, err
+		}
+		if err := decodeColor(colors.Content[1], &b); err != nil {
+			return nil, err
+		}
+	}
+
+	var pattern internal.Pattern
+
+	switch typeNode.Value {
+	case "stripes":
+		pattern = internal.NewStripePattern(a, b)
+	case "gradient":
+		pattern = internal.NewGradientPattern(a, b)
+	case "rings":
+		pattern = internal.NewRingPattern(a, b)
+	case "checkers":
+		pattern = internal.NewCheckersPattern(a, b)
+	case "test":
+		pattern = internal.NewTestPattern()
+	default:
+		return nil, errorAt(typeNode, "unknown pattern type %q", typeNode.Value)
+	}
+
+	transform, err := p.optionalTransform(node, "transform")
+
+	if err != nil {
+		return nil, err
+	}
+
+	pattern.SetTransform(transform)
+
+	return pattern, nil
+}
+
+func (p *sceneParser) optionalTransform(item *yaml.Node, key string) (internal.Matrix, error) {
+	node := mappingValue(item, key)
+
+	if node == nil {
+		return internal.NewIdentity4(), nil
+	}
+
+	return p.parseTransform(node)
+}
+
+func (p *sceneParser) parseTransform(node *yaml.Node) (internal.Matrix, error) {
+	transform := internal.NewIdentity4()
+
+	if node.Kind != yaml.SequenceNode {
+		return transform, errorAt(node, "transform must be a list")
+	}
+
+	for _, step := range node.Content {
+		var m internal.Matrix
+
+		switch step.Kind {
+		case yaml.ScalarNode:
+			defined, ok := p.transforms[step.Value]
+
+			if !ok {
+				return transform, errorAt(step, "unknown transform %q", step.Value)
+			}
+
+			m = defined
+
+		case yaml.SequenceNode:
+			op, err := parseTransformStep(step)
+
+			if err != nil {
+				return transform, err
+			}
+
+			m = op
+
+		default:
+			return transform, errorAt(step, "invalid transform step")
+		}
+
+		transform = internal.MatrixMultiply(m, transform)
+	}
+
+	return transform, nil
+}
+
+func parseTransformStep(step *yaml.Node) (internal.Matrix, error) {
+	if len(step.Content) == 0 {
+		return internal.Matrix{}, errorAt(step, "empty transform step")
+	}
+
+	name := step.Content[0].Value
+	args := make([]float64, len(step.Content)-1)
+
+	for i, argNode := range step.Content[1:] {
+		if err := argNode.Decode(&args[i]); err != nil {
+			return internal.Matrix{}, errorAt(argNode, "transform argument must be a number")
+		}
+	}
+
+	arity := map[string]int{
+		"translate": 3,
+		"scale":     3,
+		"rotate-x":  1,
+		"rotate-y":  1,
+		"rotate-z":  1,
+		"shear":     6,
+	}
+
+	n, ok := arity[name]
+
+	if !ok {
+		return internal.Matrix{}, errorAt(step, "unknown transform %q", name)
+	}
+
+	if len(args) != n {
+		return internal.Matrix{}, errorAt(step, "%s expects %d arguments, got %d", name, n, len(args))
+	}
+
+	switch name {
+	case "translate":
+		return internal.Translate(args[0], args[1], args[2]), nil
+	case "scale":
+		return internal.Scale(args[0], args[1], args[2]), nil
+	case "rotate-x":
+		return internal.RotateX(args[0]), nil
+	case "rotate-y":
+		return internal.RotateY(args[0]), nil
+	case "rotate-z":
+		return internal.RotateZ(args[0]), nil
+	default:
+		return internal.Shear(args[0], args[1], args[2], args[3], args[4], args[5]), nil
+	}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func checkKeys(node *yaml.Node, allowed ...string) error {
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i]
+		found := false
+
+		for _, a := range allowed {
+			if key.Value == a {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return errorAt(key, "unexpected key %q", key.Value)
+		}
+	}
+
+	return nil
+}
+
+func requireFloat(item *yaml.Node, key string, out *float64) error {
+	if mappingValue(item, key) == nil {
+		return errorAt(item, "missing %s", key)
+	}
+
+	return optionalFloat(item, key, out)
+}
+
+func optionalFloat(item *yaml.Node, key string, out *float64) error {
+	node := mappingValue(item, key)
+
+	if node == nil {
+		return nil
+	}
+
+	if err := node.Decode(out); err != nil {
+		return errorAt(node, "%s must be a number", key)
+	}
+
+	return nil
+}
+
+func requireInt(item *yaml.Node, key string, out *int) error {
+	node := mappingValue(item, key)
+
+	if node == nil {
+		return errorAt(item, "missing %s", key)
+	}
+
+	if err := node.Decode(out); err != nil {
+		return errorAt(node, "%s must be an integer", key)
+	}
+
+	return nil
+}
+
+func requireFloats(item *yaml.Node, key string, out *[]float64) error {
+	node := mappingValue(item, key)
+
+	if node == nil {
+		return errorAt(item, "missing %s", key)
+	}
+
+	if err := node.Decode(out); err != nil || len(*out) == 0 {
+		return errorAt(node, "%s must be a list of numbers", key)
+	}
+
+	return nil
+}
+
+func optionalBool(item *yaml.Node, key string, out *bool) error {
+	node := mappingValue(item, key)
+
+	if node == nil {
+		return nil
+	}
+
+	if err := node.Decode(out); err != nil {
+		return errorAt(node, "%s must be true or false", key)
+	}
+
+	return nil
+}
+
+func decodeTriple(node *yaml.Node, what string) (float64, float64, float64, error) {
+	var values []float64
+
+	if err := node.Decode(&values); err != nil || len(values) != 3 {
+		return 0, 0, 0, errorAt(node, "%s must be a list of three numbers", what)
+	}
+
+	return values[0], values[1], values[2], nil
+}
+
+func requirePoint(item *yaml.Node, key string, out *internal.Tuple) error {
+	node := mappingValue(item, key)
+
+	if node == nil {
+		return errorAt(item, "missing %s", key)
+	}
+
+	x, y, z, err := decodeTriple(node, key)
+
+	if err != nil {
+		return err
+	}
+
+	*out = internal.NewPoint(x, y, z)
+
+	return nil
+}
+
+func requireVector(item *yaml.Node, key string, out *internal.Tuple) error {
+	node := mappingValue(item, key)
+
+	if node == nil {
+		return errorAt(item, "missing %s", key)
+	}
+
+	x, y, z, err := decodeTriple(node, key)
+
+	if err != nil {
+		return err
+	}
+
+	*out = internal.NewVector(x, y, z)
+
+	return nil
+}
+
+func decodeColor(node *yaml.Node, out *internal.Color) error {
+	r, g, b, err := decodeTriple(node, "color")
+
+	if err != nil {
+		return err
+	}
+
+	*out = internal.NewColor(r, g, b)
+
+	return nil
+}
+
+func requireColor(item *yaml.Node, key string, out *internal.Color) error {
+	if mappingValue(item, key) == nil {
+		return errorAt(item, "missing %s", key)
+	}
+
+	return optionalColor(item, key, out)
+}
+
+func optionalColor(item *yaml.Node, key string, out *internal.Color) error {
+	node := mappingValue(item, key)
+
+	if node == nil {
+		return nil
+	}
+
+	return decodeColor(node, out)
+}
@@ -0,0 +1,891 @@
+package parser
+
+import (
+	"fmt"
+	"gotracer/internal"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const cameraYAML = `
+- add: camera
+  width: 100
+  height: 50
+  field-of-view: 0.785
+  from: [0, 1.5, -5]
+  to: [0, 1, 0]
+  up: [0, 1, 0]
+`
+
+func TestParseCamera(t *testing.T) {
+	scene, err := ParseSceneFile(cameraYAML)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 100, scene.Camera.Hsize)
+	assert.Equal(t, 50, scene.Camera.Vsize)
+	assert.InDelta(t, 0.785, scene.Camera.FOV, 1e-9)
+
+	expected := internal.ViewTransform(
+		internal.NewPoint(0, 1.5, -5),
+		internal.NewPoint(0, 1, 0),
+		internal.NewVector(0, 1, 0),
+	)
+	assert.True(t, internal.MatrixEquals(expected, scene.Camera.Transform))
+}
+
+func TestSceneWithoutCameraIsAnError(t *testing.T) {
+	_, err := ParseSceneFile(`
+- add: sphere
+`)
+
+	assert.NotNil(t, err)
+}
+
+func TestParseLights(t *testing.T) {
+	scene, err := ParseSceneFile(cameraYAML + `
+- add: light
+  at: [-10, 10, -10]
+  intensity: [1, 1, 1]
+
+- add: light
+  corner: [-1, 2, 4]
+  uvec: [2, 0, 0]
+  vvec: [0, 2, 0]
+  usteps: 10
+  vsteps: 5
+  jitter: true
+  intensity: [1.5, 1.5, 1.5]
+`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(scene.World.Lights))
+
+	point := scene.World.Lights[0].(internal.PointLight)
+	assert.True(t, internal.PointLightEquals(internal.NewPointLight(
+		internal.NewPoint(-10, 10, -10),
+		internal.NewColor(1, 1, 1),
+	), point))
+
+	area := scene.World.Lights[1].(internal.AreaLight)
+	assert.Equal(t, 10, area.USteps)
+	assert.Equal(t, 5, area.VSteps)
+	assert.Equal(t, 50, area.Samples)
+	assert.True(t, area.Jitter)
+	assert.True(t, internal.TupleEquals(internal.NewVector(0.2, 0, 0), area.UVec))
+}
+
+func TestParseBackgrounds(t *testing.T) {
+	solid, err := ParseSceneFile(cameraYAML + `
+- add: background
+  color: [0.2, 0.3, 0.4]
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, internal.NewSolidBackground(internal.NewColor(0.2, 0.3, 0.4)), solid.World.Background)
+
+	gradient, err := ParseSceneFile(cameraYAML + `
+- add: background
+  bottom: [1, 1, 1]
+  top: [0.5, 0.7, 1]
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, internal.NewGradientBackground(internal.NewColor(1, 1, 1), internal.NewColor(0.5, 0.7, 1)), gradient.World.Background)
+
+	pattern, err := ParseSceneFile(cameraYAML + `
+- add: background
+  pattern:
+    type: checkers
+    colors:
+      - [0, 0, 0]
+      - [1, 1, 1]
+`)
+	assert.Nil(t, err)
+	assert.IsType(t, internal.PatternBackground{}, pattern.World.Background)
+
+	_, err = ParseSceneFile(cameraYAML + `
+- add: background
+  top: [1, 1, 1]
+`)
+	assert.NotNil(t, err)
+}
+
+func TestParseObjRejectsFilesWithoutFaces(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "gotracer")
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "tri.obj"), []byte("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "bad.obj"), []byte("vertex 0 0 0\nface 1 2 3\n"), 0644))
+
+	scene, err := parseScene(cameraYAML+`
+- add: obj
+  file: tri.obj
+`, dir, 0, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(scene.World.Objects))
+
+	_, err = parseScene(cameraYAML+`
+- add: obj
+  file: bad.obj
+`, dir, 0, 0)
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "2 lines ignored")
+}
+
+func TestParseEnvironment(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "gotracer")
+	defer os.RemoveAll(dir)
+
+	image := internal.NewCanvas(4, 2)
+	image.WritePixelAtCoord(1, 0, internal.NewColor(2, 2, 2))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "sky.hdr"), image.ToHDR(), 0644))
+
+	scene, err := parseScene(cameraYAML+`
+- add: environment
+  file: sky.hdr
+  intensity: 1.5
+  rotation: 0.5
+  samples: 8
+`, dir, 0, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1.5, scene.World.Environment.Intensity)
+	assert.Equal(t, 0.5, scene.World.Environment.Rotation)
+	assert.Equal(t, 8, scene.World.Environment.Samples)
+	assert.Equal(t, 4, scene.World.Environment.Map.W)
+
+	_, err = parseScene(cameraYAML+`
+- add: environment
+  file: missing.hdr
+`, dir, 0, 0)
+	assert.NotNil(t, err)
+}
+
+func TestParseTransmissiveShadows(t *testing.T) {
+	scene, err := ParseSceneFile(cameraYAML)
+	assert.Nil(t, err)
+	assert.False(t, scene.World.TransmissiveShadows)
+
+	scene, err = ParseSceneFile(cameraYAML + `
+- add: shadows
+  transmissive: true
+`)
+	assert.Nil(t, err)
+	assert.True(t, scene.World.TransmissiveShadows)
+
+	_, err = ParseSceneFile(cameraYAML + `
+- add: shadows
+  coloured: true
+`)
+	assert.NotNil(t, err)
+}
+
+func TestParseMedia(t *testing.T) {
+	scene, err := ParseSceneFile(cameraYAML + `
+- add: medium
+  scattering: [0.02, 0.02, 0.02]
+  anisotropy: 0.3
+
+- add: sphere
+  material:
+    medium:
+      absorption: [0.1, 0.2, 0.3]
+      scattering: [0.5, 0.5, 0.5]
+      steps: 8
+`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0.3, scene.World.Medium.Anisotropy)
+	assert.True(t, internal.ColorEquals(internal.NewColor(0.02, 0.02, 0.02), scene.World.Medium.Scattering))
+
+	medium := scene.World.Objects[0].GetMaterial().Medium
+	assert.True(t, internal.ColorEquals(internal.NewColor(0.1, 0.2, 0.3), medium.Absorption))
+	assert.Equal(t, 8, medium.Steps)
+
+	scene, err = ParseSceneFile(cameraYAML + `
+- add: sphere
+  material:
+    transparency: 0.9
+    absorption: [0.8, 0.2, 0.1]
+    absorption-density: 1.5
+`)
+
+	assert.Nil(t, err)
+
+	material := scene.World.Objects[0].GetMaterial()
+	assert.True(t, internal.ColorEquals(internal.NewColor(0.8, 0.2, 0.1), material.Absorption))
+	assert.Equal(t, 1.5, material.AbsorptionDensity)
+
+	_, err = ParseSceneFile(cameraYAML + `
+- add: medium
+  anisotropy: 1.5
+`)
+	assert.NotNil(t, err)
+}
+
+func TestParseDispersion(t *testing.T) {
+	scene, err := ParseSceneFile(cameraYAML + `
+- add: sphere
+  material:
+    transparency: 1
+    dispersion:
+      model: cauchy
+      coefficients: [1.5, 0.004]
+- add: sphere
+  material:
+    dispersion:
+      model: sellmeier
+      b: [1.03961212, 0.231792344, 1.01046945]
+      c: [0.00600069867, 0.0200179144, 103.560653]
+`)
+
+	assert.Nil(t, err)
+
+	cauchy := scene.World.Objects[0].GetMaterial().Dispersion
+	assert.Equal(t, internal.DispersionCauchy, cauchy.Model)
+	assert.Equal(t, []float64{1.5, 0.004}, cauchy.Coefficients)
+
+	sellmeier := scene.World.Objects[1].GetMaterial().Dispersion
+	assert.Equal(t, internal.DispersionSellmeier, sellmeier.Model)
+	assert.InDelta(t, 1.5168, sellmeier.IndexAt(587.6), 1e-4)
+
+	for _, bad := range []string{"abbe\n      coefficients: [1.5]", "cauchy\n      coefficients: [1, 2, 3, 4]", "sellmeier\n      b: [1, 2]\n      c: [0.1]"} {
+		_, err = ParseSceneFile(cameraYAML + `
+- add: sphere
+  material:
+    dispersion:
+      model: ` + bad + "\n")
+		assert.NotNil(t, err, bad)
+	}
+}
+
+func TestParseFresnel(t *testing.T) {
+	scene, err := ParseSceneFile(cameraYAML + `
+- add: plane
+  material:
+    reflective: 1
+    refractive-index: 1.5
+    fresnel: dielectric
+- add: sphere
+  material:
+    reflective: 1
+    fresnel: conductor
+    conductor-eta: [0.18, 0.42, 1.37]
+    conductor-k: [3.42, 2.35, 1.77]
+`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, internal.FresnelDielectric, scene.World.Objects[0].GetMaterial().Fresnel)
+
+	metal := scene.World.Objects[1].GetMaterial()
+	assert.Equal(t, internal.FresnelConductor, metal.Fresnel)
+	assert.True(t, internal.ColorEquals(internal.NewColor(3.42, 2.35, 1.77), metal.ConductorK))
+
+	for _, bad := range []string{"fresnel: fuzzy", "fresnel: conductor", "fresnel: dielectric", "fresnel: schlick"} {
+		_, err = ParseSceneFile(cameraYAML + `
+- add: sphere
+  material:
+    ` + bad + "\n")
+		assert.NotNil(t, err, bad)
+	}
+}
+
+func TestParseOrthographicCamera(t *testing.T) {
+	scene, err := ParseSceneFile(`
+- add: camera
+  projection: orthographic
+  view-width: 6
+  width: 60
+  height: 30
+  from: [0, 0, -5]
+  to: [0, 0, 0]
+  up: [0, 1, 0]
+`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, internal.ProjectionOrthographic, scene.Camera.Projection)
+	assert.InDelta(t, 0.1, scene.Camera.PixelSize, 1e-9)
+
+	for _, bad := range []string{"projection: orthographic", "projection: orthographic\n  view-width: -1", "projection: isometric"} {
+		_, err = ParseSceneFile(cameraYAML + "  " + bad + "\n")
+		assert.NotNil(t, err, bad)
+	}
+}
+
+func TestParsePanoramicCamera(t *testing.T) {
+	scene, err := ParseSceneFile(`
+- add: camera
+  projection: equirectangular
+  width: 64
+  height: 32
+  from: [0, 0, 0]
+  to: [0, 0, 1]
+  up: [0, 1, 0]
+`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, internal.ProjectionEquirectangular, scene.Camera.Projection)
+
+	scene, err = ParseSceneFile(`
+- add: camera
+  projection: fisheye-equisolid
+  field-of-view: 3.14159
+  width: 64
+  height: 64
+  from: [0, 0, 0]
+  to: [0, 0, 1]
+  up: [0, 1, 0]
+`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, internal.ProjectionFisheyeEquisolid, scene.Camera.Projection)
+	assert.InDelta(t, 3.14159, scene.Camera.FOV, 1e-9)
+
+	_, err = ParseSceneFile(`
+- add: camera
+  projection: fisheye-equidistant
+  width: 64
+  height: 64
+  from: [0, 0, 0]
+  to: [0, 0, 1]
+  up: [0, 1, 0]
+`)
+	assert.NotNil(t, err)
+}
+
+func TestParseMotion(t *testing.T) {
+	scene, err := ParseSceneFile(cameraYAML + `
+- add: sphere
+  transform:
+    - [translate, -1, 0, 0]
+  end-transform:
+    - [translate, 1, 0, 0]
+`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, scene.Camera.ShutterClose)
+
+	motion := scene.World.Objects[0].GetMotion()
+	assert.NotNil(t, motion)
+	assert.True(t, internal.MatrixEquals(internal.Translate(-1, 0, 0), motion.Start))
+	assert.True(t, internal.MatrixEquals(internal.Translate(1, 0, 0), motion.End))
+	assert.True(t, internal.MatrixEquals(internal.Translate(-1, 0, 0), scene.World.Objects[0].GetTransform()))
+
+	scene, err = ParseSceneFile(cameraYAML + "  shutter-open: 0.25\n  shutter-close: 0.5\n")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0.25, scene.Camera.ShutterOpen)
+	assert.Equal(t, 0.5, scene.Camera.ShutterClose)
+	assert.Nil(t, scene.World.Objects)
+
+	for _, bad := range []string{"shutter-open: 0.5\n  shutter-close: 0.25", "shutter-close: 2", "shutter-open: -1"} {
+		_, err = ParseSceneFile(cameraYAML + "  " + bad + "\n")
+		assert.NotNil(t, err, bad)
+	}
+}
+
+func TestParseLensCamera(t *testing.T) {
+	scene, err := ParseSceneFile(`
+- add: camera
+  width: 11
+  height: 11
+  field-of-view: 1.5708
+  from: [0, 0, -5]
+  to: [0, 0, 0]
+  up: [0, 1, 0]
+  f-stop: 2
+  focal-length: 0.4
+  blades: 6
+  autofocus: true
+- add: sphere
+`)
+
+	assert.Nil(t, err)
+	assert.InDelta(t, 0.1, scene.Camera.Aperture, 1e-9)
+	assert.InDelta(t, 4.0, scene.Camera.FocalDistance, 1e-4)
+	assert.Equal(t, 6, scene.Camera.Blades)
+
+	for _, bad := range []string{"aperture: 0.1\n  f-stop: 2\n  focal-length: 1", "f-stop: 2", "blades: 2", "autofocus: true"} {
+		_, err = ParseSceneFile(cameraYAML + "  " + bad + "\n")
+		assert.NotNil(t, err, bad)
+	}
+}
+
+func TestParseVolume(t *testing.T) {
+	scene, err := ParseSceneFile(cameraYAML + `
+- add: group
+  transform:
+    - [translate, 0, 2, 0]
+  children:
+    - add: volume
+      min: [-2, -1, -2]
+      max: [2, 1, 2]
+      density:
+        type: noise
+        scale: 0.5
+        octaves: 3
+        threshold: 0.1
+        gain: 4
+      scattering: [0.8, 0.8, 0.8]
+      anisotropy: 0.4
+      method: delta-tracking
+`)
+
+	assert.Nil(t, err)
+
+	group := scene.World.Objects[0].(*internal.Group)
+	volume := group.Children[0].(*internal.Volume)
+	assert.Equal(t, internal.VolumeDeltaTracking, volume.Method)
+	assert.InDelta(t, (1.75*1.05-0.1)*4, volume.MaxDensity, 1e-9)
+	assert.True(t, internal.TupleEquals(internal.NewPoint(2, 1, 2), volume.Bounds.Max))
+
+	noise := volume.Density.(internal.NoiseDensity)
+	assert.Equal(t, 3, noise.Octaves)
+	assert.Equal(t, 0.5, noise.Scale)
+
+	_, err = ParseSceneFile(cameraYAML + `
+- add: volume
+  density:
+    type: marble
+`)
+	assert.NotNil(t, err)
+
+	scene, err = ParseSceneFile(cameraYAML + `
+- add: volume
+  density:
+    type: constant
+    value: 2
+  max-density: 3
+  shadow: false
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, 3.0, scene.World.Objects[0].(*internal.Volume).MaxDensity)
+	assert.False(t, scene.World.Objects[0].CastsShadow())
+
+	_, err = ParseSceneFile(cameraYAML + `
+- add: volume
+  density:
+    type: constant
+    value: 2
+  max-density: 1
+`)
+	assert.NotNil(t, err)
+}
+
+func TestParseShapesWithTransformsAndMaterials(t *testing.T) {
+	scene, err := ParseSceneFile(cameraYAML + `
+- add: sphere
+  transform:
+    - [scale, 2, 2, 2]
+    - [translate, 1, 0, 0]
+  material:
+    color: [1, 0, 0]
+    diffuse: 0.7
+    reflective: 0.3
+    refractive-index: 1.5
+
+- add: cylinder
+  min: 0
+  max: 2
+  closed: true
+  shadow: false
+`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(scene.World.Objects))
+
+	sphere := scene.World.Objects[0].(*internal.Sphere)
+	expected := internal.MatrixMultiply(internal.Translate(1, 0, 0), internal.Scale(2, 2, 2))
+	assert.True(t, internal.MatrixEquals(expected, sphere.Transform))
+	assert.True(t, internal.ColorEquals(internal.NewColor(1, 0, 0), sphere.Material.Color))
+	assert.Equal(t, 0.7, sphere.Material.Diffuse)
+	assert.Equal(t, 0.3, sphere.Material.Reflective)
+	assert.Equal(t, 1.5, sphere.Material.RefractiveIndex)
+	assert.Equal(t, internal.DefaultMaterial.Ambient, sphere.Material.Ambient)
+
+	cyl := scene.World.Objects[1].(*internal.Cylinder)
+	assert.Equal(t, 0.0, cyl.Minimum)
+	assert.Equal(t, 2.0, cyl.Maximum)
+	assert.True(t, cyl.Closed)
+	assert.False(t, cyl.CastsShadow())
+}
+
+func TestParseDefinesAndExtends(t *testing.T) {
+	scene, err := ParseSceneFile(cameraYAML + `
+- define: white-material
+  value:
+    color: [1, 1, 1]
+    diffuse: 0.7
+    ambient: 0.1
+
+- define: blue-material
+  extend: white-material
+  value:
+    color: [0.5, 0.8, 0.9]
+
+- define: standard-transform
+  value:
+    - [translate, 1, -1, 1]
+    - [scale, 0.5, 0.5, 0.5]
+
+- define: large-object
+  value:
+    - standard-transform
+    - [scale, 3.5, 3.5, 3.5]
+
+- add: cube
+  material: blue-material
+  transform:
+    - large-object
+    - [translate, 4, 0, 0]
+`)
+
+	assert.Nil(t, err)
+
+	cube := scene.World.Objects[0].(*internal.Cube)
+	assert.True(t, internal.ColorEquals(internal.NewColor(0.5, 0.8, 0.9), cube.Material.Color))
+	assert.Equal(t, 0.7, cube.Material.Diffuse)
+
+	expected := internal.MatrixMultiply(
+		internal.Translate(4, 0, 0),
+		internal.MatrixMultiply(
+			internal.Scale(3.5, 3.5, 3.5),
+			internal.MatrixMultiply(
+				internal.Scale(0.5, 0.5, 0.5),
+				internal.Translate(1, -1, 1),
+			),
+		),
+	)
+	assert.True(t, internal.MatrixEquals(expected, cube.Transform))
+}
+
+func TestParsePatterns(t *testing.T) {
+	scene, err := ParseSceneFile(cameraYAML + `
+- add: plane
+  material:
+    pattern:
+      type: checkers
+      colors:
+        - [0, 0, 0]
+        - [1, 1, 1]
+      transform:
+        - [scale, 0.5, 0.5, 0.5]
+`)
+
+	assert.Nil(t, err)
+
+	plane := scene.World.Objects[0].(*internal.Plane)
+	pattern := plane.Material.Pattern.(*internal.CheckersPattern)
+	assert.True(t, internal.ColorEquals(internal.NewColor(0, 0, 0), pattern.A))
+	assert.True(t, internal.ColorEquals(internal.NewColor(1, 1, 1), pattern.B))
+	assert.True(t, internal.MatrixEquals(internal.Scale(0.5, 0.5, 0.5), pattern.Transform))
+}
+
+func TestParseGroupsAndDefinedShapes(t *testing.T) {
+	scene, err := ParseSceneFile(cameraYAML + `
+- define: leg
+  value:
+    add: group
+    children:
+      - add: sphere
+      - add: cylinder
+        min: 0
+        max: 1
+    transform:
+      - [rotate-y, 1.5707963]
+
+- add: leg
+  transform:
+    - [translate, 0, 1, 0]
+
+- add: leg
+`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(scene.World.Objects))
+
+	g1 := scene.World.Objects[0].(*internal.Group)
+	g2 := scene.World.Objects[1].(*internal.Group)
+
+	assert.Equal(t, 2, len(g1.Children))
+	assert.NotEqual(t, g1.GetID(), g2.GetID())
+	assert.True(t, internal.MatrixEquals(internal.RotateY(1.5707963), g2.Transform))
+	assert.True(t, internal.MatrixEquals(
+		internal.MatrixMultiply(internal.Translate(0, 1, 0), internal.RotateY(1.5707963)),
+		g1.Transform,
+	))
+	assert.Equal(t, internal.Shape(g1), g1.Children[0].GetParent())
+}
+
+func TestParseCSG(t *testing.T) {
+	scene, err := ParseSceneFile(cameraYAML + `
+- add: csg
+  operation: difference
+  left:
+    add: cube
+  right:
+    add: sphere
+    transform:
+      - [scale, 1.3, 1.3, 1.3]
+`)
+
+	assert.Nil(t, err)
+
+	csg := scene.World.Objects[0].(*internal.CSG)
+	assert.Equal(t, internal.CSGDifference, csg.Operation)
+	assert.IsType(t, &internal.Cube{}, csg.Left)
+	assert.IsType(t, &internal.Sphere{}, csg.Right)
+}
+
+func TestParseTriangles(t *testing.T) {
+	scene, err := ParseSceneFile(cameraYAML + `
+- add: triangle
+  p1: [0, 1, 0]
+  p2: [-1, 0, 0]
+  p3: [1, 0, 0]
+`)
+
+	assert.Nil(t, err)
+
+	tri := scene.World.Objects[0].(*internal.Triangle)
+	assert.True(t, internal.TupleEquals(internal.NewPoint(-1, 0, 0), tri.P2))
+}
+
+func TestRenderingParsedScene(t *testing.T) {
+	scene, err := ParseSceneFile(`
+- add: camera
+  width: 11
+  height: 11
+  field-of-view: 1.5707963267948966
+  from: [0, 0, -5]
+  to: [0, 0, 0]
+  up: [0, 1, 0]
+
+- add: light
+  at: [-10, 10, -10]
+  intensity: [1, 1, 1]
+
+- add: sphere
+  material:
+    color: [0.8, 1.0, 0.6]
+    diffuse: 0.7
+    specular: 0.2
+
+- add: sphere
+  transform:
+    - [scale, 0.5, 0.5, 0.5]
+`)
+
+	assert.Nil(t, err)
+
+	image := internal.Render(scene.Camera, scene.World)
+	pixelColor := image.GetColorAtPixel(5, 5)
+
+	assert.InDelta(t, 0.38066, pixelColor.R, 1e-4)
+	assert.InDelta(t, 0.47583, pixelColor.G, 1e-4)
+	assert.InDelta(t, 0.28550, pixelColor.B, 1e-4)
+	assert.InDelta(t, math.Pi/2, scene.Camera.FOV, 1e-9)
+}
+
+func TestParseRecursiveTransformDefinesFail(t *testing.T) {
+	_, err := ParseSceneFile(cameraYAML + `
+- define: a
+  value: [a]
+- add: sphere
+  transform: [a]
+`)
+
+	assert.NotNil(t, err)
+
+	_, err = ParseSceneFile(cameraYAML + `
+- define: a
+  value: [b]
+- define: b
+  value: [[scale, 2, 2, 2], a]
+- add: sphere
+  transform: [b]
+`)
+
+	assert.NotNil(t, err)
+}
+
+func TestParseResolvesEachDefineOnce(t *testing.T) {
+	scene := cameraYAML + `
+- define: t0
+  value: [[translate, 1, 0, 0]]
+- define: s0
+  value:
+    add: sphere
+    transform: [[scale, 2, 2, 2]]
+`
+
+	for i := 1; i <= 30; i++ {
+		scene += fmt.Sprintf("- define: t%d\n  value: [t%d, t%d]\n", i, i-1, i-1)
+		scene += fmt.Sprintf("- define: s%d\n  value:\n    add: s%d\n    transform: [t%d]\n", i, i-1, i-1)
+	}
+
+	parsed, err := ParseSceneFile(scene + `
+- add: s30
+  transform: [t30]
+`)
+
+	assert.Nil(t, err)
+
+	expected := internal.MatrixMultiply(internal.Translate(1<<31-1, 0, 0), internal.Scale(2, 2, 2))
+	assert.True(t, internal.MatrixEquals(expected, parsed.World.Objects[0].GetTransform()))
+}
+
+func TestParsedShapeIDsDoNotDependOnEarlierScenes(t *testing.T) {
+	scene := cameraYAML + `
+- add: plane
+- add: group
+  children:
+    - add: sphere
+`
+	first, err := ParseSceneFile(scene)
+	assert.Nil(t, err)
+
+	second, err := ParseSceneFile(scene)
+	assert.Nil(t, err)
+
+	assert.Equal(t, int64(1), first.World.Objects[0].GetID())
+	assert.Equal(t, first.World.Objects[1].GetID(), second.World.Objects[1].GetID())
+	assert.Equal(t, int64(3), second.World.Objects[1].(*internal.Group).Children[0].GetID())
+}
+
+func TestSceneErrorsReportLineNumbers(t *testing.T) {
+	testCases := []struct {
+		scene string
+		line  int
+	}{
+		{cameraYAML + "\n- add: teapot\n", 10},
+		{cameraYAML + "\n- add: sphere\n  colour: [1, 0, 0]\n", 11},
+		{cameraYAML + "\n- add: sphere\n  transform:\n    - [translate, 1, 2]\n", 12},
+		{cameraYAML + "\n- add: sphere\n  material: missing\n", 11},
+	}
+
+	for _, test := range testCases {
+		_, err := ParseSceneFile(test.scene)
+
+		sceneErr, ok := err.(*SceneError)
+		assert.True(t, ok)
+		assert.Equal(t, test.line, sceneErr.Line)
+	}
+}
+
+const keyframedYAML = `
+- add: camera
+  width: 10
+  height: 10
+  field-of-view: 0.785
+  from:
+    keyframes:
+      - { time: 0, value: [0, 0, -5] }
+      - { time: 2, value: [0, 0, -9] }
+  to: [0, 0, 0]
+  up: [0, 1, 0]
+- add: light
+  at: [0, 10, 0]
+  intensity:
+    keyframes:
+      - { time: 0, value: [1, 1, 1], interpolation: step }
+      - { time: 1, value: [0.5, 0.5, 0.5] }
+- add: sphere
+  transform:
+    keyframes:
+      - time: 0
+        value:
+          - [translate, 0, 0, 0]
+      - time: 2
+        value:
+          - [translate, 4, 0, 0]
+  material:
+    reflective:
+      keyframes:
+        - { time: 0, value: 0, interpolation: bezier, ease: [0.25, 0.25, 0.75, 0.75] }
+        - { time: 2, value: 0.8 }
+`
+
+func TestParseKeyframes(t *testing.T) {
+	scene, err := ParseSceneFileAt(keyframedYAML, 1, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2.0, scene.Duration)
+	assert.True(t, internal.MatrixEquals(internal.ViewTransform(internal.NewPoint(0, 0, -7), internal.NewPoint(0, 0, 0),
+		internal.NewVector(0, 1, 0)), scene.Camera.Transform))
+	assert.Equal(t, internal.NewColor(0.5, 0.5, 0.5), scene.World.Lights[0].GetIntensity())
+
+	sphere := scene.World.Objects[0]
+	assert.True(t, internal.MatrixEquals(internal.Translate(2, 0, 0), sphere.GetTransform()))
+	assert.InDelta(t, 0.4, sphere.GetMaterial().Reflective, 1e-6)
+	assert.Nil(t, sphere.GetMotion())
+	assert.Equal(t, 0.0, scene.Camera.ShutterClose)
+
+	scene, err = ParseSceneFileAt(keyframedYAML, 0.5, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, internal.NewColor(1, 1, 1), scene.World.Lights[0].GetIntensity())
+
+	scene, err = ParseSceneFile(keyframedYAML)
+
+	assert.Nil(t, err)
+	assert.True(t, internal.MatrixEquals(internal.Translate(0, 0, 0), scene.World.Objects[0].GetTransform()))
+
+	scene, err = ParseSceneFileAt(strings.Replace(keyframedYAML, "width: 10", "width: { keyframes: [{ time: 0, value: 10 }, { time: 1, value: 21 }] }", 1), 0.5, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 16, scene.Camera.Hsize)
+}
+
+func TestParseKeyframesAddsFrameMotion(t *testing.T) {
+	scene, err := ParseSceneFileAt(keyframedYAML, 1, 0.5)
+
+	assert.Nil(t, err)
+	motion := scene.World.Objects[0].GetMotion()
+	assert.NotNil(t, motion)
+	assert.True(t, internal.MatrixEquals(internal.Translate(2, 0, 0), motion.Start))
+	assert.True(t, internal.MatrixEquals(internal.Translate(3, 0, 0), motion.End))
+	assert.Equal(t, 1.0, scene.Camera.ShutterClose)
+
+	scene, err = ParseSceneFileAt(cameraYAML+`
+- add: sphere
+  transform:
+    - [scale, 2, 2, 2]
+    - [translate, { keyframes: [{ time: 0, value: 0 }, { time: 2, value: 4 }] }, 0, 0]
+`, 1, 0.5)
+
+	assert.Nil(t, err)
+	motion = scene.World.Objects[0].GetMotion()
+	assert.NotNil(t, motion)
+	assert.True(t, internal.MatrixEquals(internal.MatrixMultiply(internal.Translate(2, 0, 0), internal.Scale(2, 2, 2)), motion.Start))
+	assert.True(t, internal.MatrixEquals(internal.MatrixMultiply(internal.Translate(3, 0, 0), internal.Scale(2, 2, 2)), motion.End))
+}
+
+func TestParseKeyframeErrors(t *testing.T) {
+	for _, bad := range []string{
+		"reflective: { keyframes: [] }",
+		"reflective: { keyframes: [{ time: 0 }] }",
+		"reflective: { keyframes: [{ time: 1, value: 0 }, { time: 0, value: 1 }] }",
+		"reflective: { keyframes: [{ time: 0, value: 0, interpolation: cubic }] }",
+		"reflective: { keyframes: [{ time: 0, value: 0, ease: [0, 1] }] }",
+		"color: { keyframes: [{ time: 0, value: [1, 0, 0] }, { time: 1, value: 1 }] }",
+	} {
+		_, err := ParseSceneFile(cameraYAML + "- add: sphere\n  material:\n    " + bad + "\n")
+		assert.NotNil(t, err, bad)
+	}
+
+	_, err := ParseSceneFile(cameraYAML + `
+- add: sphere
+  transform:
+    keyframes:
+      - { time: 0, value: [[translate, 0, 0, 0]], interpolation: step }
+      - { time: 1, value: [[scale, 2, 2, 2], [translate, 1, 0, 0]] }
+`)
+	assert.Nil(t, err)
+}